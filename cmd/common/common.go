@@ -1,6 +1,8 @@
 package common
 
 import (
+	"os"
+
 	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/terraformcli"
 	"github.com/mitchellh/go-homedir"
@@ -13,48 +15,120 @@ import (
 // all commands.
 var Fs afero.Fs
 
-// InitializeTerraform initializes terraform object with correct parameters. This initialization
-// is used across all commands that needs it.
+// InitializeTerraform initializes terraform object with correct parameters, downloading
+// its binary if needed. This initialization is used across all commands that needs it.
 func InitializeTerraform(deployment deployment.Deployment) (*terraformcli.Terraform, error) {
+	terraform, err := OpenTerraform(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = terraform.Install()
+	if err != nil {
+		return nil, err
+	}
+
+	return terraform, nil
+}
+
+// OpenTerraform returns a terraform object with the same parameters than InitializeTerraform,
+// but without checking or downloading its binary.
+func OpenTerraform(deployment deployment.Deployment) (*terraformcli.Terraform, error) {
 	terraformPath, err := homedir.Expand(viper.GetString("TerraformPath"))
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't get terraform file path")
 	}
 
-	return terraformcli.New(Fs, terraformPath, deployment.TerraformVersion(), viper.GetString("BinaryArch"))
-}
+	engine, err := terraformcli.GetEngine(deployment.Engine())
+	if err != nil {
+		return nil, err
+	}
+
+	mirror := viper.GetString("TerraformMirror")
+	if mirror != "" {
+		mirror, err = homedir.Expand(mirror)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't get terraform mirror path")
+		}
+		engine = engine.WithMirror(mirror)
+	}
+
+	signingKey, err := readSigningKey(viper.GetString("TerraformSigningKey"))
+	if err != nil {
+		return nil, err
+	}
+	if signingKey != "" {
+		engine.SigningKey = signingKey
+	}
+
+	localBinary := viper.GetString("TerraformBinary")
+	if localBinary != "" {
+		localBinary, err = homedir.Expand(localBinary)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't get terraform binary path")
+		}
+	}
 
-// GetCurrentDeployment returns the current deployment name saved on the current file, or
-// the override provided as argument if is set.
-func GetCurrentDeployment(override string) (string, error) {
-	if override != "" {
-		return override, nil
+	arch := viper.GetString("BinaryArch")
+	if arch == "" {
+		arch, err = terraformcli.HostArch()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	filename, err := homedir.Expand("~/.sonatina/current")
+	terraform := terraformcli.Open(Fs, terraformPath, engine, deployment.TerraformVersion(), arch, localBinary)
+
+	providerMirror, err := GetProviderMirrorPath()
 	if err != nil {
-		return "", errors.Wrap(err, "couldn't expand directory")
+		return nil, err
 	}
+	if providerMirror != "" {
+		terraform.SetProviderMirror(providerMirror)
+	}
+	terraform.SetLogPath(deployment.LogsPath())
 
-	deployData, err := afero.ReadFile(Fs, filename)
+	// keep standard output for the machine readable result
+	if OutputFormat != OutputTable {
+		terraform.SetOutput(os.Stderr)
+	}
+
+	return terraform, nil
+}
+
+// GetProviderMirrorPath returns the configured provider mirror directory, or empty
+// string if providers must be installed from the registry.
+func GetProviderMirrorPath() (string, error) {
+	providerMirror := viper.GetString("ProviderMirror")
+	if providerMirror == "" {
+		return "", nil
+	}
+
+	providerMirror, err := homedir.Expand(providerMirror)
 	if err != nil {
-		return "", errors.Wrap(err, "couldn't read current deployment")
+		return "", errors.Wrapf(err, "couldn't get provider mirror path")
 	}
 
-	return string(deployData), nil
+	return providerMirror, nil
 }
 
-// SetCurrentDeployment sets a current deployment saving it to the current file.
-func SetCurrentDeployment(deployName string) error {
-	filename, err := homedir.Expand("~/.sonatina/current")
+// readSigningKey returns the content of the armored public key file used to verify
+// terraform releases, or empty string if no file is configured and the key embedded
+// for the engine must be used.
+func readSigningKey(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	path, err := homedir.Expand(path)
 	if err != nil {
-		return errors.Wrap(err, "couldn't expand directory")
+		return "", errors.Wrap(err, "couldn't expand signing key path")
 	}
 
-	err = afero.WriteFile(Fs, filename, []byte(deployName), 0644)
+	key, err := afero.ReadFile(Fs, path)
 	if err != nil {
-		return errors.Wrap(err, "couldn't write current deployment")
+		return "", errors.Wrapf(err, "couldn't read signing key %s", path)
 	}
 
-	return nil
+	return string(key), nil
 }
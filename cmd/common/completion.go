@@ -0,0 +1,229 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/spf13/cobra"
+)
+
+// completionFunc is the signature of cobra dynamic completion functions
+type completionFunc func(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// RegisterFlagCompletions walks the command tree registering the dynamic completions
+// of the flags shared by sonatina commands. Flags that depend on a deployment are only
+// completed on commands with the --deployment flag.
+func RegisterFlagCompletions(command *cobra.Command) {
+	flags := map[string]completionFunc{
+		"deployment":     CompleteDeployments,
+		"user-component": CompleteUsercomponents,
+		"component":      CompleteInstances,
+		"component-type": CompleteComponentTypes,
+		"plugin":         CompletePlugins,
+		"flavour":        CompleteFlavours,
+	}
+
+	if command.LocalNonPersistentFlags().Lookup("deployment") != nil {
+		for name, f := range flags {
+			if command.LocalNonPersistentFlags().Lookup(name) != nil {
+				// error is ignored, it's only returned if the flag doesn't exist
+				_ = command.RegisterFlagCompletionFunc(name, f)
+			}
+		}
+	}
+
+	for _, subcommand := range command.Commands() {
+		RegisterFlagCompletions(subcommand)
+	}
+}
+
+// CompleteDeployments completes deployment names
+func CompleteDeployments(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := manager.GetManager().List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompleteUsercomponents completes the user components
+func CompleteUsercomponents(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names, err := deploy.ListComponents(deployment.ComponentUser)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompleteInstances completes the instances of the component type set with
+// --component-type, or the user components if it isn't set
+func CompleteInstances(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	component := flagValue(command, "component-type")
+	if component == "" {
+		component = deployment.ComponentUser
+	}
+
+	names, err := deploy.ListComponents(component)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompleteComponentTypes completes the component types with instances, that are
+// user and the ones declared by the base CTD
+func CompleteComponentTypes(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	types, err := deploy.ListComponentTypes()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(types[1:], toComplete)
+}
+
+// CompleteComponents completes the component type as first argument, and the
+// instances of that type as second argument
+func CompleteComponents(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return CompleteComponentTypes(command, args, toComplete)
+	}
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names, err := deploy.ListComponents(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompletePlugins completes the plugins of the component selected with --component-type,
+// --component and --user-component, or the global plugins if no component is selected
+func CompletePlugins(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	component, instance, err := ResolveComponent(flagValue(command, "component-type"), flagValue(command, "component"), flagValue(command, "user-component"))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	if component == deployment.ComponentGlobal {
+		names, err = deploy.ListPluginsGlobal()
+	} else {
+		names, err = deploy.ListPluginsComponent(component, instance)
+	}
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompleteGlobalPlugins completes the global plugins that can be added to the component
+// selected with --component-type, --component and --user-component. Nothing is completed
+// for the global component, as new global plugins need a new name.
+func CompleteGlobalPlugins(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if flagValue(command, "component") == "" && flagValue(command, "user-component") == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names, err := deploy.ListPluginsGlobal()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompleteFlavours completes the flavours declared by the base CTD or configured
+// on the deployment
+func CompleteFlavours(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	deploy, err := completionDeployment(command)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names, err := deploy.ListFlavours()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completions(names, toComplete)
+}
+
+// CompleteFirstArg applies a completion function to the first argument only
+func CompleteFirstArg(f completionFunc) completionFunc {
+	return func(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return f(command, args, toComplete)
+	}
+}
+
+// completionDeployment returns the deployment set with --deployment, or the current one
+func completionDeployment(command *cobra.Command) (deployment.Deployment, error) {
+	deployName, err := GetCurrentDeployment(flagValue(command, "deployment"))
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.GetManager().Get(deployName)
+}
+
+func flagValue(command *cobra.Command, name string) string {
+	flag := command.Flags().Lookup(name)
+	if flag == nil {
+		return ""
+	}
+	return flag.Value.String()
+}
+
+// completions returns the sorted names starting with toComplete
+func completions(names []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	result := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/pkg/errors"
+)
+
+// ResolveComponent returns the component type and instance selected by the
+// --component-type and --component flags. Without component type, the instance
+// is an user component, or the global component if it's empty too. The user
+// component set with --user-component can't be used with other component types.
+func ResolveComponent(componentType string, instance string, user string) (string, string, error) {
+	if user != "" {
+		if instance != "" {
+			return "", "", errors.New("--component and --user-component can't be used together")
+		}
+		if componentType != "" && componentType != deployment.ComponentUser {
+			return "", "", errors.Errorf("--user-component can't be used with %s component type, use --component", componentType)
+		}
+		return deployment.ComponentUser, user, nil
+	}
+
+	switch componentType {
+	case "":
+		if instance == "" {
+			return deployment.ComponentGlobal, "", nil
+		}
+		return deployment.ComponentUser, instance, nil
+	case deployment.ComponentGlobal:
+		if instance != "" {
+			return "", "", errors.New("global component doesn't have instances")
+		}
+		return deployment.ComponentGlobal, "", nil
+	default:
+		if instance == "" {
+			return "", "", errors.Errorf("a %s component name must be set with --component", componentType)
+		}
+		return componentType, instance, nil
+	}
+}
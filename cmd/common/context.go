@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Timeout limits the execution time of a command. Zero means no limit.
+var Timeout time.Duration
+
+// Context returns a context that is cancelled when sonatina receives an interrupt
+// or termination signal, or when Timeout expires. Running terraform processes
+// are interrupted when the context is done.
+func Context() (context.Context, context.CancelFunc) {
+	signalCtx, cancelSignal := context.WithCancel(context.Background())
+
+	ctx, cancelTimeout := signalCtx, context.CancelFunc(func() {})
+	if Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(signalCtx, Timeout)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case sig := <-signals:
+			logrus.WithField("signal", sig).Warn("cancelling execution")
+			fmt.Fprintf(os.Stderr, "Received %v, cancelling execution...\n", sig)
+			cancelSignal()
+		case <-signalCtx.Done():
+		}
+	}()
+
+	cancel := func() {
+		cancelTimeout()
+		cancelSignal()
+	}
+	return ctx, cancel
+}
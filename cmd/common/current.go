@@ -0,0 +1,158 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// DeploymentEnvVar is the environment variable that sets the current deployment
+const DeploymentEnvVar string = "SONATINA_DEPLOYMENT"
+
+// LocalContextFileName is the file that sets the current deployment for the directory
+// where it's saved and its subdirectories
+const LocalContextFileName string = ".sonatina"
+
+// Sources of the current deployment, from highest to lowest precedence
+const (
+	SourceFlag        string = "flag"
+	SourceEnvironment string = "environment"
+	SourceDirectory   string = "directory"
+	SourceGlobal      string = "global"
+)
+
+// CurrentDeployment is the deployment commands work on when --deployment isn't set,
+// and where it's configured. Path is the context file of directory and global sources.
+type CurrentDeployment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Path   string `json:"path,omitempty"`
+}
+
+// GetCurrentDeployment returns the current deployment name, or the override provided
+// as argument if is set.
+func GetCurrentDeployment(override string) (string, error) {
+	current, err := ResolveCurrentDeployment(override)
+	if err != nil {
+		return "", err
+	}
+
+	return current.Name, nil
+}
+
+// ResolveCurrentDeployment returns the current deployment and its source. The override
+// provided as argument takes precedence, followed by SONATINA_DEPLOYMENT environment
+// variable, the nearest .sonatina file walking up from the working directory, and
+// finally the global current file.
+func ResolveCurrentDeployment(override string) (*CurrentDeployment, error) {
+	if override != "" {
+		return &CurrentDeployment{Name: override, Source: SourceFlag}, nil
+	}
+
+	if name := strings.TrimSpace(os.Getenv(DeploymentEnvVar)); name != "" {
+		return &CurrentDeployment{Name: name, Source: SourceEnvironment}, nil
+	}
+
+	path, err := findLocalContextFile()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		name, err := readContextFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &CurrentDeployment{Name: name, Source: SourceDirectory, Path: path}, nil
+	}
+
+	path, err = globalContextFile()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := readContextFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CurrentDeployment{Name: name, Source: SourceGlobal, Path: path}, nil
+}
+
+// SetCurrentDeployment sets a current deployment saving it to the current file.
+func SetCurrentDeployment(deployName string) error {
+	filename, err := globalContextFile()
+	if err != nil {
+		return err
+	}
+
+	err = afero.WriteFile(Fs, filename, []byte(deployName), 0644)
+	if err != nil {
+		return errors.Wrap(err, "couldn't write current deployment")
+	}
+
+	return nil
+}
+
+// SetLocalDeployment sets the current deployment for the working directory, saving
+// it to a .sonatina file. Returns the path of the file.
+func SetLocalDeployment(deployName string) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get working directory")
+	}
+
+	filename := filepath.Join(dir, LocalContextFileName)
+	err = afero.WriteFile(Fs, filename, []byte(deployName+"\n"), 0644)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't write context file %s", filename)
+	}
+
+	return filename, nil
+}
+
+// findLocalContextFile returns the path of the nearest .sonatina file walking up from
+// the working directory, or empty string if there isn't any. Directories named
+// .sonatina, like the one on home directory, are skipped.
+func findLocalContextFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get working directory")
+	}
+
+	for {
+		path := filepath.Join(dir, LocalContextFileName)
+		// unreadable directories are skipped, like the ones without context file
+		info, err := Fs.Stat(path)
+		if err == nil && !info.IsDir() {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func globalContextFile() (string, error) {
+	filename, err := homedir.Expand("~/.sonatina/current")
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't expand directory")
+	}
+
+	return filename, nil
+}
+
+func readContextFile(path string) (string, error) {
+	data, err := afero.ReadFile(Fs, path)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't read current deployment from %s", path)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,12 @@
+package common
+
+// ExitError is returned by commands that must finish with a specific exit code,
+// to be used by scripts or scheduled jobs.
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (err ExitError) Error() string {
+	return err.Message
+}
@@ -0,0 +1,67 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Formats supported by the --output flag
+const (
+	OutputTable string = "table"
+	OutputJSON  string = "json"
+	OutputYAML  string = "yaml"
+)
+
+// OutputFormat is the format set by the --output flag. It's in package common to be
+// shared across all commands.
+var OutputFormat string = OutputTable
+
+// PrintOutput prints data on the format set by the --output flag. Data is printed
+// by the table function on table format, and marshaled using its json tags on json
+// and yaml formats, so both of them share the same schema.
+func PrintOutput(data interface{}, table func() error) error {
+	switch OutputFormat {
+	case OutputJSON:
+		bytes, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "couldn't marshal json")
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(bytes))
+		return err
+	case OutputYAML:
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			return errors.Wrap(err, "couldn't marshal json")
+		}
+
+		// JSON is valid YAML, so it's decoded again to keep the json schema
+		var value interface{}
+		err = yaml.Unmarshal(bytes, &value)
+		if err != nil {
+			return errors.Wrap(err, "couldn't unmarshal yaml")
+		}
+
+		bytes, err = yaml.Marshal(value)
+		if err != nil {
+			return errors.Wrap(err, "couldn't marshal yaml")
+		}
+		_, err = fmt.Fprint(os.Stdout, string(bytes))
+		return err
+	default:
+		return table()
+	}
+}
+
+// ValidateOutputFormat returns an error if the --output flag format isn't supported
+func ValidateOutputFormat() error {
+	switch OutputFormat {
+	case OutputTable, OutputJSON, OutputYAML:
+		return nil
+	}
+	return errors.Errorf("unknown output format %s, must be one of %s, %s or %s",
+		OutputFormat, OutputTable, OutputJSON, OutputYAML)
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/pkg/errors"
+)
+
+// SelectUsercomponents returns the sorted names of the deployment user components
+// matching the label selector. An error is returned if none matches.
+func SelectUsercomponents(deploy deployment.Deployment, selector string) ([]string, error) {
+	s, err := deployment.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := deploy.ListUsercomponentsWithSelector(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errors.Errorf("no user components match selector %s", selector)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,47 @@
+package component
+
+import (
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateComponent declares `sonatina create component` command
+var CreateComponent = &cobra.Command{
+	Use:               "component TYPE NAME",
+	Short:             "Add a component of a type declared by the base CTD to deployment",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteComponentTypes),
+	RunE:              createComponentExecution,
+}
+
+func init() {
+	CreateComponent.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+}
+
+func createComponentExecution(command *cobra.Command, args []string) error {
+	componentType := args[0]
+	componentName := args[1]
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	err = deploy.CreateComponent(componentType, componentName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Created")
+	return nil
+}
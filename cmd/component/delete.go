@@ -0,0 +1,89 @@
+package component
+
+import (
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/utils"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+)
+
+// DeleteComponent declares `sonatina delete component` command
+var DeleteComponent = &cobra.Command{
+	Use:               "component TYPE NAME",
+	Short:             "Remove a component of a type declared by the base CTD from deployment",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: common.CompleteComponents,
+	RunE:              deleteComponentExecution,
+}
+
+func init() {
+	DeleteComponent.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	DeleteComponent.Flags().BoolVar(&destroy, "destroy", false, "destroy the component resources before deleting it")
+	DeleteComponent.Flags().StringVarP(&message, "message", "m", "", "commit message for the destroy operation")
+}
+
+func deleteComponentExecution(command *cobra.Command, args []string) error {
+	componentType := args[0]
+	componentName := args[1]
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	components, err := deploy.ListComponents(componentType)
+	if err != nil {
+		return err
+	}
+	if _, ok := utils.FindString(components, componentName); !ok {
+		return errors.Errorf("%s component %s doesn't exist", componentType, componentName)
+	}
+
+	resources, err := deploy.ListManagedResourcesComponent(componentType, componentName)
+	if err != nil {
+		return err
+	}
+
+	if len(resources) > 0 {
+		if !destroy {
+			return errors.Errorf("%s component %s manages %d resources, use --destroy to destroy them before deleting it",
+				componentType, componentName, len(resources))
+		}
+
+		terraform, err := common.InitializeTerraform(deploy)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := common.Context()
+		defer cancel()
+
+		if message == "" {
+			message = fmt.Sprintf("destroy %s component %s", componentType, componentName)
+		}
+
+		err = workflow.Destroy(terraform, deploy).RunComponent(ctx, message, componentType, componentName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = deploy.DeleteComponent(componentType, componentName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Deleted")
+	return nil
+}
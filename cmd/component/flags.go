@@ -0,0 +1,6 @@
+package component
+
+//To define flags
+var deployName string
+var destroy bool
+var message string
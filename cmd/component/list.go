@@ -0,0 +1,73 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+
+	"github.com/spf13/cobra"
+)
+
+// ListComponents declares `sonatina list components` command
+var ListComponents = &cobra.Command{
+	Use:               "components [TYPE]",
+	Short:             "List the deployment component types, or the components of a type",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteComponentTypes),
+	RunE:              listComponentsExecution,
+}
+
+// componentInfo is the structured output of `sonatina list components`
+type componentInfo struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	ListComponents.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+}
+
+func listComponentsExecution(command *cobra.Command, args []string) error {
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	var header string
+	var list []string
+	if len(args) == 0 {
+		header = "COMPONENT TYPES:"
+		list, err = deploy.ListComponentTypes()
+	} else {
+		header = strings.ToUpper(args[0]) + " COMPONENTS:"
+		list, err = deploy.ListComponents(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	result := []componentInfo{}
+	for _, element := range list {
+		result = append(result, componentInfo{Name: element})
+	}
+
+	return common.PrintOutput(result, func() error {
+		fmt.Fprintln(os.Stdout, header)
+		for _, element := range list {
+			_, err = fmt.Fprintln(os.Stdout, element)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
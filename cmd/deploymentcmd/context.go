@@ -0,0 +1,39 @@
+package deploymentcmd
+
+import (
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+// GetContext declares `sonatina get context` command
+var GetContext = &cobra.Command{
+	Use:   "context",
+	Short: "Obtain the current deployment and where it's configured",
+	Long: `Obtain the deployment used when --deployment isn't set, and its source: the
+SONATINA_DEPLOYMENT environment variable, the nearest .sonatina file walking up
+from the working directory, or the global current deployment.`,
+	Args: cobra.NoArgs,
+	RunE: getContextExecution,
+}
+
+func getContextExecution(command *cobra.Command, args []string) error {
+	current, err := common.ResolveCurrentDeployment("")
+	if err != nil {
+		return err
+	}
+
+	return common.PrintOutput(current, func() error {
+		_, err := fmt.Printf("%s (%s)\n", current.Name, describeContextSource(current))
+		return err
+	})
+}
+
+// describeContextSource returns the source of the current deployment with its context file
+func describeContextSource(current *common.CurrentDeployment) string {
+	if current.Path == "" {
+		return current.Source
+	}
+	return fmt.Sprintf("%s %s", current.Source, current.Path)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,6 +28,7 @@ func init() {
 
 	CreateDeployment.Flags().StringVarP(&codeRepoPath, "code-repo-path", "p", "", "code git repo path")
 	CreateDeployment.Flags().StringVarP(&terraformVersion, "terraform-version", "t", "", "terraform version")
+	CreateDeployment.Flags().StringVarP(&engine, "engine", "e", "", "terraform engine (terraform or opentofu)")
 	CreateDeployment.Flags().StringVarP(&flavour, "flavour", "f", "", "flavour")
 }
 
@@ -41,8 +43,16 @@ func createDeploymentExecution(command *cobra.Command, args []string) error {
 	if terraformVersion == "" {
 		terraformVersion = viper.GetString("DefaultTerraformVersion")
 	}
+	if engine == "" {
+		engine = viper.GetString("DefaultEngine")
+	}
+
+	_, err := terraformcli.GetEngine(engine)
+	if err != nil {
+		return err
+	}
 
-	err := m.Create(deployName, storageRepoURI, codeRepoURI, codeRepoPath, terraformVersion, flavour)
+	err = m.Create(deployName, storageRepoURI, codeRepoURI, codeRepoPath, terraformVersion, engine, flavour)
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package deploymentcmd
 import (
 	"fmt"
 
+	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
 
 	"github.com/spf13/cobra"
@@ -10,10 +11,11 @@ import (
 
 // DeleteDeployment declares `sonatina delete deployment` command
 var DeleteDeployment = &cobra.Command{
-	Use:   "deployment",
-	Short: "Delete a specified deployment from local",
-	Args:  cobra.ExactArgs(1),
-	RunE:  deleteDeploymentExecution,
+	Use:               "deployment",
+	Short:             "Delete a specified deployment from local",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteDeployments),
+	RunE:              deleteDeploymentExecution,
 }
 
 func deleteDeploymentExecution(command *cobra.Command, args []string) error {
@@ -5,4 +5,6 @@ var storageRepoURI string
 var codeRepoURI string
 var codeRepoPath string
 var terraformVersion string
+var engine string
 var flavour string
+var local bool
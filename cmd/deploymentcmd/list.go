@@ -3,6 +3,7 @@ package deploymentcmd
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
@@ -17,6 +18,13 @@ var ListDeployment = &cobra.Command{
 	RunE:  listDeploymentExecution,
 }
 
+// deploymentInfo is the structured output of `sonatina list deployments`
+type deploymentInfo struct {
+	Name           string `json:"name"`
+	Current        bool   `json:"current"`
+	StorageRepoURI string `json:"storage_repo_uri"`
+}
+
 func listDeploymentExecution(command *cobra.Command, args []string) error {
 	m := manager.GetManager()
 
@@ -24,23 +32,40 @@ func listDeploymentExecution(command *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	sort.Strings(list)
 
 	current, err := common.GetCurrentDeployment("")
 	if err != nil {
 		logrus.WithError(err).Warning("couldn't get current deployment")
 	}
 
-	fmt.Fprintln(os.Stdout, "DEPLOYMENTS:")
+	deployments := []deploymentInfo{}
 	for _, element := range list {
-		prefix := " - "
-		if element == current {
-			prefix = " * "
-		}
-		_, err = fmt.Fprintln(os.Stdout, prefix+element)
+		storageRepoURI, err := m.StorageRepoURI(element)
 		if err != nil {
 			return err
 		}
+
+		deployments = append(deployments, deploymentInfo{
+			Name:           element,
+			Current:        element == current,
+			StorageRepoURI: storageRepoURI,
+		})
 	}
 
-	return nil
+	return common.PrintOutput(deployments, func() error {
+		fmt.Fprintln(os.Stdout, "DEPLOYMENTS:")
+		for _, element := range deployments {
+			prefix := " - "
+			if element.Current {
+				prefix = " * "
+			}
+			_, err = fmt.Fprintln(os.Stdout, prefix+element.Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
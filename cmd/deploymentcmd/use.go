@@ -2,6 +2,7 @@ package deploymentcmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
@@ -13,8 +14,17 @@ import (
 var UseDeployment = &cobra.Command{
 	Use:   "deployment",
 	Short: "Configure a default deployment to do operations",
-	Args:  cobra.ExactArgs(1),
-	RunE:  useDeploymentExecution,
+	Long: `Configure a default deployment to do operations, used when --deployment isn't
+set. It's saved globally, or on a .sonatina file of the working directory with
+--local, that applies to its subdirectories too. SONATINA_DEPLOYMENT environment
+variable takes precedence over both, and a local context over the global one.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteDeployments),
+	RunE:              useDeploymentExecution,
+}
+
+func init() {
+	UseDeployment.Flags().BoolVar(&local, "local", false, "configure the deployment for the working directory")
 }
 
 func useDeploymentExecution(command *cobra.Command, args []string) error {
@@ -26,11 +36,27 @@ func useDeploymentExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	err = common.SetCurrentDeployment(deployName)
+	if local {
+		path, err := common.SetLocalDeployment(deployName)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Configured on %s\n", path)
+	} else {
+		err = common.SetCurrentDeployment(deployName)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Configured")
+	}
+
+	current, err := common.ResolveCurrentDeployment("")
 	if err != nil {
 		return err
 	}
+	if current.Name != deployName {
+		fmt.Fprintf(os.Stderr, "Warning: deployment %s from %s context takes precedence\n", current.Name, describeContextSource(current))
+	}
 
-	fmt.Println("Configured")
 	return nil
 }
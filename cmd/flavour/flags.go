@@ -3,3 +3,5 @@ package flavour
 //To define flags
 var deployName string
 var userComponent string
+var componentInstance string
+var componentType string
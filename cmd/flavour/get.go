@@ -15,9 +15,18 @@ var GetFlavour = &cobra.Command{
 	RunE:  getFlavourExecution,
 }
 
+// flavourInfo is the structured output of `sonatina get flavour`
+type flavourInfo struct {
+	Component string `json:"component"`
+	Instance  string `json:"instance,omitempty"`
+	Flavour   string `json:"flavour"`
+}
+
 func init() {
 	GetFlavour.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	GetFlavour.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	GetFlavour.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	GetFlavour.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
 }
 
 func getFlavourExecution(command *cobra.Command, args []string) error {
@@ -34,18 +43,19 @@ func getFlavourExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	if userComponent == "" {
-		flavour, err = deploy.GetFlavourGlobal()
-		if err != nil {
-			return err
-		}
-	} else {
-		flavour, err = deploy.GetFlavourUser(userComponent)
-		if err != nil {
-			return err
-		}
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return err
+	}
+
+	flavour, err = deploy.GetFlavourComponent(component, instance)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println(flavour)
-	return nil
+	result := flavourInfo{Component: component, Instance: instance, Flavour: flavour}
+	return common.PrintOutput(result, func() error {
+		fmt.Println(flavour)
+		return nil
+	})
 }
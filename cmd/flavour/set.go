@@ -10,15 +10,18 @@ import (
 
 // SetFlavour declares `sonatina set flavour` command
 var SetFlavour = &cobra.Command{
-	Use:   "flavour",
-	Short: "Configure a specified flavour",
-	Args:  cobra.ExactArgs(1),
-	RunE:  setFlavourExecution,
+	Use:               "flavour",
+	Short:             "Configure a specified flavour",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteFlavours),
+	RunE:              setFlavourExecution,
 }
 
 func init() {
 	SetFlavour.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	SetFlavour.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	SetFlavour.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	SetFlavour.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
 }
 
 func setFlavourExecution(command *cobra.Command, args []string) error {
@@ -35,16 +38,14 @@ func setFlavourExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	if userComponent == "" {
-		err = deploy.SetFlavourGlobal(flavour)
-		if err != nil {
-			return err
-		}
-	} else {
-		err = deploy.SetFlavourUser(flavour, userComponent)
-		if err != nil {
-			return err
-		}
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return err
+	}
+
+	err = deploy.SetFlavourComponent(flavour, component, instance)
+	if err != nil {
+		return err
 	}
 
 	fmt.Println("Configured")
@@ -0,0 +1,6 @@
+package mirror
+
+//To define flags
+var deployName string
+var mirrorPath string
+var platforms []string
@@ -0,0 +1,98 @@
+package mirror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// SyncMirror declares `sonatina mirror sync` command
+var SyncMirror = &cobra.Command{
+	Use:   "sync",
+	Short: "Download the providers needed by a deployment to the provider mirror",
+	Args:  cobra.NoArgs,
+	RunE:  syncMirrorExecution,
+}
+
+func init() {
+	SyncMirror.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	SyncMirror.Flags().StringVar(&mirrorPath, "path", "", "provider mirror directory (default ProviderMirror configuration)")
+	SyncMirror.Flags().StringSliceVar(&platforms, "platform", []string{}, "target platform, like linux_amd64 (default host platform)")
+}
+
+func syncMirrorExecution(command *cobra.Command, args []string) error {
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	if mirrorPath == "" {
+		mirrorPath, err = common.GetProviderMirrorPath()
+		if err != nil {
+			return err
+		}
+		if mirrorPath == "" {
+			return errors.New("required flag \"path\" or ProviderMirror configuration not set")
+		}
+	}
+
+	if len(platforms) == 0 {
+		platform, err := terraformcli.HostArch()
+		if err != nil {
+			return err
+		}
+		platforms = append(platforms, platform)
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	terraform, err := common.InitializeTerraform(deploy)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := common.Context()
+	defer cancel()
+
+	mirror := workflow.Mirror(terraform, deploy)
+	err = mirror.RunGlobal(ctx, mirrorPath, platforms)
+	if err != nil {
+		return err
+	}
+
+	componentTypes, err := deploy.ListComponentTypes()
+	if err != nil {
+		return err
+	}
+
+	for _, componentType := range componentTypes {
+		if componentType == deployment.ComponentGlobal {
+			continue
+		}
+
+		instances, err := deploy.ListComponents(componentType)
+		if err != nil {
+			return err
+		}
+
+		for _, instance := range instances {
+			err = mirror.RunComponent(ctx, mirrorPath, platforms, componentType, instance)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println("Synchronized")
+	return nil
+}
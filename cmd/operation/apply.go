@@ -4,6 +4,7 @@ import (
 	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
 	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +20,9 @@ func init() {
 	Apply.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	Apply.Flags().BoolVarP(&pull, "pull", "p", false, "enable pull before apply")
 	Apply.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Apply.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Apply.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	Apply.Flags().StringVarP(&selector, "selector", "l", "", "apply the user components matching the label selector")
 }
 
 func applyExecution(command *cobra.Command, args []string) error {
@@ -35,11 +39,24 @@ func applyExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
+	users, err := selectUsers(deploy)
+	if err != nil {
+		return err
+	}
+
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
 	terraform, err := common.InitializeTerraform(deploy)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := common.Context()
+	defer cancel()
+
 	if pull {
 		err = deploy.Pull()
 		if err != nil {
@@ -48,10 +65,15 @@ func applyExecution(command *cobra.Command, args []string) error {
 	}
 
 	apply := workflow.Apply(terraform, deploy)
-	if userComponent == "" {
-		err = apply.RunGlobal(message)
+	if selector != "" {
+		for _, user := range users {
+			err = apply.RunUser(ctx, message, user)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't apply user component %s", user)
+			}
+		}
 	} else {
-		err = apply.RunUser(message, userComponent)
+		err = apply.RunComponent(ctx, message, component, instance)
 	}
 	if err != nil {
 		return err
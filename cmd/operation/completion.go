@@ -0,0 +1,34 @@
+package operation
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Completion declares `sonatina completion` command
+var Completion = &cobra.Command{
+	Use:   "completion bash|zsh|fish",
+	Short: "Generate the shell completion script",
+	Long: `Generate the completion script of sonatina for the specified shell. Completions
+include deployment, component, plugin and flavour names on all shells. To load
+completions on the current shell:
+
+  bash:  source <(sonatina completion bash)
+  zsh:   source <(sonatina completion zsh); compdef _sonatina sonatina
+  fish:  sonatina completion fish | source`,
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Args:      cobra.ExactValidArgs(1),
+	RunE:      completionExecution,
+}
+
+func completionExecution(command *cobra.Command, args []string) error {
+	switch args[0] {
+	case "bash":
+		return command.Root().GenBashCompletion(os.Stdout)
+	case "zsh":
+		return command.Root().GenZshCompletion(os.Stdout)
+	default:
+		return command.Root().GenFishCompletion(os.Stdout, true)
+	}
+}
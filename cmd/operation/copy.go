@@ -0,0 +1,16 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/usercomponent"
+	"github.com/spf13/cobra"
+)
+
+// Copy declares `sonatina copy` command
+var Copy = &cobra.Command{
+	Use:   "copy",
+	Short: "Create a resource copying an existing one",
+}
+
+func init() {
+	Copy.AddCommand(usercomponent.CopyUsercomponent)
+}
@@ -1,6 +1,7 @@
 package operation
 
 import (
+	"github.com/arodriguezdlc/sonatina/cmd/component"
 	"github.com/arodriguezdlc/sonatina/cmd/deploymentcmd"
 	"github.com/arodriguezdlc/sonatina/cmd/plugin"
 	"github.com/arodriguezdlc/sonatina/cmd/usercomponent"
@@ -17,4 +18,5 @@ func init() {
 	Delete.AddCommand(deploymentcmd.DeleteDeployment)
 	Delete.AddCommand(usercomponent.DeleteUsercomponent)
 	Delete.AddCommand(plugin.DeletePlugin)
+	Delete.AddCommand(component.DeleteComponent)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
 	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +19,9 @@ var Destroy = &cobra.Command{
 func init() {
 	Destroy.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	Destroy.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Destroy.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Destroy.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	Destroy.Flags().StringVarP(&selector, "selector", "l", "", "destroy the user components matching the label selector")
 }
 
 func destroyExecution(command *cobra.Command, args []string) error {
@@ -34,16 +38,34 @@ func destroyExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
+	users, err := selectUsers(deploy)
+	if err != nil {
+		return err
+	}
+
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
 	terraform, err := common.InitializeTerraform(deploy)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := common.Context()
+	defer cancel()
+
 	destroy := workflow.Destroy(terraform, deploy)
-	if userComponent == "" {
-		err = destroy.RunGlobal(message)
+	if selector != "" {
+		for _, user := range users {
+			err = destroy.RunUser(ctx, message, user)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't destroy user component %s", user)
+			}
+		}
 	} else {
-		err = destroy.RunUser(message, userComponent)
+		err = destroy.RunComponent(ctx, message, component, instance)
 	}
 	if err != nil {
 		return err
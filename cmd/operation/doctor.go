@@ -0,0 +1,159 @@
+package operation
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/spf13/cobra"
+)
+
+// DoctorExitCode is the exit code of `sonatina doctor` when problems remain unfixed
+const DoctorExitCode int = 3
+
+// Doctor declares `sonatina doctor` command
+var Doctor = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the local storage of deployments",
+	Long: fmt.Sprintf(`Check that deployments file entries match the deployment directories, the
+variables and state repositories have the right branches checked out, metadata
+parses, plugin code matches the metadata plugins, workdirs belong to existing
+components and the terraform binary is present and verified. All deployments
+are checked unless one is specified. Use --fix for safe repairs. Exits with code
+%d if any problem remains unfixed.`, DoctorExitCode),
+	Args: cobra.NoArgs,
+	RunE: doctorExecution,
+}
+
+func init() {
+	Doctor.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	Doctor.Flags().BoolVar(&fix, "fix", false, "repair the problems that can be fixed safely")
+}
+
+func doctorExecution(command *cobra.Command, args []string) error {
+	m := manager.GetManager()
+	problems := []deployment.Problem{}
+
+	deployNames := []string{deployName}
+	if deployName == "" {
+		registryProblems, err := m.DiagnoseRegistry(fix)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, registryProblems...)
+
+		deployNames, err = m.List()
+		if err != nil {
+			return err
+		}
+		sort.Strings(deployNames)
+	}
+
+	for _, name := range deployNames {
+		deployProblems, err := m.Diagnose(name, fix)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, deployProblems...)
+
+		if !problemsFixed(deployProblems) {
+			continue
+		}
+
+		terraformProblem, err := diagnoseTerraform(m, name)
+		if err != nil {
+			return err
+		}
+		if terraformProblem != nil {
+			problems = append(problems, *terraformProblem)
+		}
+	}
+
+	err := printProblems(problems)
+	if err != nil {
+		return err
+	}
+
+	if !problemsFixed(problems) {
+		return common.ExitError{Code: DoctorExitCode, Message: "problems found"}
+	}
+
+	return nil
+}
+
+// diagnoseTerraform checks that the terraform binary of the deployment is present
+// and verified, downloading it again if --fix is set
+func diagnoseTerraform(m manager.Manager, name string) (*deployment.Problem, error) {
+	deploy, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	terraform, err := common.OpenTerraform(deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := terraform.VerifyBinary()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, nil
+	}
+
+	problem := &deployment.Problem{
+		Deployment: name,
+		Check:      deployment.CheckTerraform,
+		Message:    fmt.Sprintf("terraform binary %s is missing or doesn't match its checksum", terraform.BinaryPath()),
+		Fixable:    true,
+	}
+
+	if fix {
+		err = terraform.Install()
+		if err != nil {
+			problem.Message = fmt.Sprintf("%s: couldn't fix: %v", problem.Message, err)
+		} else {
+			problem.Fixed = true
+		}
+	}
+
+	return problem, nil
+}
+
+func problemsFixed(problems []deployment.Problem) bool {
+	for _, problem := range problems {
+		if !problem.Fixed {
+			return false
+		}
+	}
+	return true
+}
+
+func printProblems(problems []deployment.Problem) error {
+	return common.PrintOutput(problems, func() error {
+		if len(problems) == 0 {
+			fmt.Fprintln(os.Stdout, "No problems found")
+			return nil
+		}
+
+		for _, problem := range problems {
+			state := ""
+			if problem.Fixed {
+				state = " [fixed]"
+			} else if problem.Fixable {
+				state = " [fixable with --fix]"
+			}
+
+			_, err := fmt.Fprintf(os.Stdout, "%s: %s: %s%s\n", problem.Deployment, problem.Check, problem.Message, state)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
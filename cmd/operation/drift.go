@@ -0,0 +1,144 @@
+package operation
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// DriftExitCode is the exit code of `sonatina drift` when drift is found
+const DriftExitCode int = 2
+
+// Drift declares `sonatina drift` command
+var Drift = &cobra.Command{
+	Use:   "drift",
+	Short: "Detect changes made to infrastructure outside of sonatina",
+	Long: fmt.Sprintf(`Run a refresh-only plan on deployment components, reporting the resources
+whose real infrastructure doesn't match the state. Exits with code %d if any drift is found.`, DriftExitCode),
+	Args: cobra.NoArgs,
+	RunE: driftExecution,
+}
+
+func init() {
+	Drift.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	Drift.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Drift.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Drift.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	Drift.Flags().BoolVar(&allUsers, "all-users", false, "check also every user component")
+	Drift.Flags().StringVarP(&selector, "selector", "l", "", "check the user components matching the label selector")
+	Drift.Flags().BoolVar(&jsonOutput, "json", false, "print report in json format, same as --output json")
+}
+
+func driftExecution(command *cobra.Command, args []string) error {
+	// --json flag is kept as a shortcut of --output json. It's set before initializing
+	// terraform, so its output is printed to stderr instead of the report.
+	if jsonOutput {
+		common.OutputFormat = common.OutputJSON
+	}
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	users, err := selectUsers(deploy)
+	if err != nil {
+		return err
+	}
+
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
+	terraform, err := common.InitializeTerraform(deploy)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := common.Context()
+	defer cancel()
+
+	drift := workflow.Drift(terraform, deploy)
+	report := []*workflow.ComponentDrift{}
+
+	if component != deployment.ComponentUser && selector == "" {
+		componentDrift, err := drift.RunComponent(ctx, component, instance)
+		if err != nil {
+			return err
+		}
+		report = append(report, componentDrift)
+	}
+
+	if allUsers {
+		users, err = deploy.ListUsercomponents()
+		if err != nil {
+			return err
+		}
+		sort.Strings(users)
+	} else if component == deployment.ComponentUser {
+		users = append(users, instance)
+	}
+
+	for _, user := range users {
+		componentDrift, err := drift.RunUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		report = append(report, componentDrift)
+	}
+
+	err = printDriftReport(report)
+	if err != nil {
+		return err
+	}
+
+	for _, componentDrift := range report {
+		if componentDrift.HasDrift() {
+			return common.ExitError{Code: DriftExitCode, Message: "drift detected"}
+		}
+	}
+
+	return nil
+}
+
+func printDriftReport(report []*workflow.ComponentDrift) error {
+	return common.PrintOutput(report, func() error {
+		for _, componentDrift := range report {
+			name := componentDrift.Component
+			if componentDrift.User != "" {
+				name = name + "/" + componentDrift.User
+			} else if componentDrift.Instance != "" {
+				name = name + "/" + componentDrift.Instance
+			}
+
+			if !componentDrift.HasDrift() {
+				fmt.Fprintf(os.Stdout, "%s: no drift\n", name)
+				continue
+			}
+
+			fmt.Fprintf(os.Stdout, "%s: %d resources drifted\n", name, len(componentDrift.Resources))
+			for _, resource := range componentDrift.Resources {
+				_, err := fmt.Fprintf(os.Stdout, "  ~ %s (%s)\n", resource.Address, strings.Join(resource.Actions, ", "))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
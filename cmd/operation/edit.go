@@ -22,6 +22,8 @@ func init() {
 	Edit.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 
 	Edit.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Edit.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Edit.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
 	Edit.Flags().StringVarP(&pluginName, "plugin", "p", "", "plugin")
 }
 
@@ -37,7 +39,12 @@ func editExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	filepath, err := deploy.GetVariableFilepath("config", pluginName, userComponent)
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
+	filepath, err := deploy.GetVariableFilepathComponent("config", pluginName, component, instance)
 	if err != nil {
 		return err
 	}
@@ -5,3 +5,10 @@ var deployName string
 var pluginName string
 var pull bool
 var userComponent string
+var componentInstance string
+var componentType string
+var allUsers bool
+var jsonOutput bool
+var selector string
+var noFetch bool
+var fix bool
@@ -1,6 +1,7 @@
 package operation
 
 import (
+	"github.com/arodriguezdlc/sonatina/cmd/deploymentcmd"
 	"github.com/arodriguezdlc/sonatina/cmd/flavour"
 	"github.com/spf13/cobra"
 )
@@ -12,5 +13,6 @@ var Get = &cobra.Command{
 }
 
 func init() {
+	Get.AddCommand(deploymentcmd.GetContext)
 	Get.AddCommand(flavour.GetFlavour)
 }
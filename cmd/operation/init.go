@@ -17,6 +17,8 @@ var Init = &cobra.Command{
 func init() {
 	Init.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	Init.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Init.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Init.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
 }
 
 func initExecution(command *cobra.Command, args []string) error {
@@ -31,17 +33,21 @@ func initExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
 	terraform, err := common.InitializeTerraform(deploy)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := common.Context()
+	defer cancel()
+
 	init := workflow.Init(terraform, deploy)
-	if userComponent == "" {
-		err = init.RunGlobal()
-	} else {
-		err = init.RunUser(userComponent)
-	}
+	err = init.RunComponent(ctx, component, instance)
 	if err != nil {
 		return err
 	}
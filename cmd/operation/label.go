@@ -0,0 +1,16 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/usercomponent"
+	"github.com/spf13/cobra"
+)
+
+// Label declares `sonatina label` command
+var Label = &cobra.Command{
+	Use:   "label",
+	Short: "Manage labels of a resource",
+}
+
+func init() {
+	Label.AddCommand(usercomponent.LabelUsercomponent)
+}
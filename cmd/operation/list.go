@@ -1,6 +1,7 @@
 package operation
 
 import (
+	"github.com/arodriguezdlc/sonatina/cmd/component"
 	"github.com/arodriguezdlc/sonatina/cmd/deploymentcmd"
 	"github.com/arodriguezdlc/sonatina/cmd/plugin"
 	"github.com/arodriguezdlc/sonatina/cmd/usercomponent"
@@ -17,4 +18,5 @@ func init() {
 	List.AddCommand(deploymentcmd.ListDeployment)
 	List.AddCommand(usercomponent.ListUsercomponents)
 	List.AddCommand(plugin.ListPlugins)
+	List.AddCommand(component.ListComponents)
 }
@@ -0,0 +1,16 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/mirror"
+	"github.com/spf13/cobra"
+)
+
+// Mirror declares `sonatina mirror` command
+var Mirror = &cobra.Command{
+	Use:   "mirror",
+	Short: "Manage the local provider mirror",
+}
+
+func init() {
+	Mirror.AddCommand(mirror.SyncMirror)
+}
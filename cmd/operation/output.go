@@ -0,0 +1,137 @@
+package operation
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// Output declares `sonatina output` command
+var Output = &cobra.Command{
+	Use:   "output",
+	Short: "Print the outputs of deployment components",
+	Long: `Print the outputs of the global component, an user component or the user
+components matching a label selector. Sensitive values are hidden unless printed
+in json format.`,
+	Args: cobra.NoArgs,
+	RunE: outputExecution,
+}
+
+// componentOutputs are the outputs of a component. User is set for user components,
+// and Instance for the other component types declared by the base CTD.
+type componentOutputs struct {
+	User     string                              `json:"user,omitempty"`
+	Instance string                              `json:"instance,omitempty"`
+	Outputs  map[string]terraformcli.OutputValue `json:"outputs"`
+}
+
+func init() {
+	Output.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	Output.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Output.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Output.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	Output.Flags().StringVarP(&selector, "selector", "l", "", "print the outputs of the user components matching the label selector")
+	Output.Flags().BoolVar(&jsonOutput, "json", false, "print outputs in json format, same as --output json")
+}
+
+func outputExecution(command *cobra.Command, args []string) error {
+	// --json flag is kept as a shortcut of --output json. It's set before initializing
+	// terraform, so its output is printed to stderr instead of the report.
+	if jsonOutput {
+		common.OutputFormat = common.OutputJSON
+	}
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	users, err := selectUsers(deploy)
+	if err != nil {
+		return err
+	}
+
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+	if component == deployment.ComponentUser {
+		users = append(users, instance)
+	}
+
+	terraform, err := common.InitializeTerraform(deploy)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := common.Context()
+	defer cancel()
+
+	output := workflow.Output(terraform, deploy)
+	result := []componentOutputs{}
+
+	if len(users) == 0 {
+		outputs, err := output.RunComponent(ctx, component, instance)
+		if err != nil {
+			return err
+		}
+		result = append(result, componentOutputs{Instance: instance, Outputs: outputs})
+	}
+
+	for _, user := range users {
+		outputs, err := output.RunUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		result = append(result, componentOutputs{User: user, Outputs: outputs})
+	}
+
+	return printOutputs(result)
+}
+
+func printOutputs(result []componentOutputs) error {
+	return common.PrintOutput(result, func() error {
+		for i, component := range result {
+			indent := ""
+			if component.User != "" && len(result) > 1 {
+				if i > 0 {
+					fmt.Fprintln(os.Stdout)
+				}
+				fmt.Fprintf(os.Stdout, "%s:\n", component.User)
+				indent = "  "
+			}
+
+			names := []string{}
+			for name := range component.Outputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				value := component.Outputs[name].String()
+				if component.Outputs[name].Sensitive {
+					value = "<sensitive>"
+				}
+				_, err := fmt.Fprintf(os.Stdout, "%s%s = %s\n", indent, name, value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
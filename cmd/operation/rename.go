@@ -0,0 +1,16 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/usercomponent"
+	"github.com/spf13/cobra"
+)
+
+// Rename declares `sonatina rename` command
+var Rename = &cobra.Command{
+	Use:   "rename",
+	Short: "Change the name of a resource",
+}
+
+func init() {
+	Rename.AddCommand(usercomponent.RenameUsercomponent)
+}
@@ -0,0 +1,34 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/pkg/errors"
+)
+
+// selectUsers returns the user components matching the selector flag, or an
+// empty list if it isn't set
+func selectUsers(deploy deployment.Deployment) ([]string, error) {
+	if selector == "" {
+		return []string{}, nil
+	}
+	if userComponent != "" || componentInstance != "" || allUsers {
+		return nil, errors.New("selector can't be used together with a user component or all users")
+	}
+
+	return common.SelectUsercomponents(deploy, selector)
+}
+
+// resolveComponent returns the component type and instance selected by the
+// component type, component and user component flags
+func resolveComponent() (string, string, error) {
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return "", "", err
+	}
+	if component != deployment.ComponentUser && component != deployment.ComponentGlobal && (selector != "" || allUsers) {
+		return "", "", errors.New("selector and all users can only be used with user components")
+	}
+
+	return component, instance, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/manager"
 	"github.com/spf13/cobra"
 )
@@ -16,9 +17,20 @@ var Show = &cobra.Command{
 	RunE:  showExecution,
 }
 
+// variablesInfo is the structured output of `sonatina show`
+type variablesInfo struct {
+	Kind      string                 `json:"kind"`
+	Component string                 `json:"component"`
+	Instance  string                 `json:"instance,omitempty"`
+	Plugin    string                 `json:"plugin,omitempty"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
 func init() {
 	Show.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	Show.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	Show.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	Show.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
 	Show.Flags().StringVarP(&pluginName, "plugin", "p", "", "plugin")
 }
 
@@ -36,11 +48,30 @@ func showExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	content, err := deploy.ReadVariableFile(kind, pluginName, userComponent)
+	component, instance, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
+	content, err := deploy.ReadVariableFileComponent(kind, pluginName, component, instance)
 	if err != nil {
 		return err
 	}
 
-	fmt.Print(content)
-	return nil
+	variables, err := deployment.ParseTfvars(content)
+	if err != nil {
+		return err
+	}
+
+	result := variablesInfo{
+		Kind:      kind,
+		Component: component,
+		Instance:  instance,
+		Plugin:    pluginName,
+		Variables: variables,
+	}
+	return common.PrintOutput(result, func() error {
+		fmt.Print(content)
+		return nil
+	})
 }
@@ -0,0 +1,20 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/state"
+	"github.com/spf13/cobra"
+)
+
+// State declares `sonatina state` command
+var State = &cobra.Command{
+	Use:   "state",
+	Short: "Advanced state management of a component",
+}
+
+func init() {
+	State.AddCommand(state.ListState)
+	State.AddCommand(state.ShowState)
+	State.AddCommand(state.MoveState)
+	State.AddCommand(state.RemoveState)
+	State.AddCommand(state.ImportState)
+}
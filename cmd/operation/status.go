@@ -0,0 +1,117 @@
+package operation
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/spf13/cobra"
+)
+
+// Status declares `sonatina status` command
+var Status = &cobra.Command{
+	Use:   "status",
+	Short: "Print an overview of a deployment",
+	Long: `Print the code and terraform version of a deployment, its components with the
+last apply of each one, and the divergence of the variables and state branches
+from the remote storage repository, with the uncommitted local changes.`,
+	Args: cobra.NoArgs,
+	RunE: statusExecution,
+}
+
+func init() {
+	Status.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	Status.Flags().BoolVar(&noFetch, "no-fetch", false, "don't fetch the storage repository before comparing branches")
+}
+
+func statusExecution(command *cobra.Command, args []string) error {
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	status, err := deploy.Status(!noFetch)
+	if err != nil {
+		return err
+	}
+
+	return common.PrintOutput(status, func() error {
+		return printStatus(status)
+	})
+}
+
+func printStatus(status *deployment.Status) error {
+	fmt.Fprintf(os.Stdout, "Deployment: %s\n", status.Name)
+	fmt.Fprintf(os.Stdout, "Code:       %s %s\n", status.Code.Repo, status.Code.RepoPath)
+	fmt.Fprintf(os.Stdout, "Ref:        %s (%s)\n", status.Code.Ref, shortCommit(status.Code.Commit))
+	if status.Code.Version != "" {
+		fmt.Fprintf(os.Stdout, "Version:    %s\n", status.Code.Version)
+	}
+	fmt.Fprintf(os.Stdout, "Terraform:  %s (%s)\n", status.TerraformVersion, status.Engine)
+	fmt.Fprintf(os.Stdout, "Flavour:    %s\n", status.Flavour)
+
+	fmt.Fprintln(os.Stdout, "\nPlugins:")
+	if len(status.Plugins) == 0 {
+		fmt.Fprintln(os.Stdout, "  none")
+	}
+	for _, plugin := range status.Plugins {
+		version := plugin.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(os.Stdout, "  %s %s %s (%s)\n", plugin.Name, version, plugin.Ref, shortCommit(plugin.Commit))
+	}
+
+	fmt.Fprintln(os.Stdout, "\nComponents:")
+	for _, component := range status.Components {
+		name := component.Component
+		if component.Instance != "" {
+			name = name + "/" + component.Instance
+		}
+		plugins := strings.Join(component.Plugins, ",")
+		if plugins == "" {
+			plugins = "-"
+		}
+		fmt.Fprintf(os.Stdout, "  %s flavour=%s plugins=%s\n", name, component.Flavour, plugins)
+
+		if component.LastApply == nil {
+			fmt.Fprintln(os.Stdout, "    last apply: never")
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "    last apply: %s by %s: %s\n",
+			component.LastApply.Date.Format("2006-01-02 15:04:05"), component.LastApply.Author, component.LastApply.Message)
+	}
+
+	fmt.Fprintln(os.Stdout, "\nStorage:")
+	printBranchStatus("variables", status.Variables)
+	printBranchStatus("state", status.State)
+
+	return nil
+}
+
+func printBranchStatus(name string, status deployment.BranchStatus) {
+	if status.Error != "" {
+		fmt.Fprintf(os.Stdout, "  %s: couldn't compare with remote: %s\n", name, status.Error)
+	} else {
+		fmt.Fprintf(os.Stdout, "  %s: %d ahead, %d behind\n", name, status.Ahead, status.Behind)
+	}
+	for _, file := range status.Uncommitted {
+		fmt.Fprintf(os.Stdout, "    modified: %s\n", file)
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
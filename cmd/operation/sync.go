@@ -0,0 +1,16 @@
+package operation
+
+import (
+	"github.com/arodriguezdlc/sonatina/cmd/usercomponent"
+	"github.com/spf13/cobra"
+)
+
+// Sync declares `sonatina sync` command
+var Sync = &cobra.Command{
+	Use:   "sync",
+	Short: "Synchronize resources with a declaration file",
+}
+
+func init() {
+	Sync.AddCommand(usercomponent.SyncUsercomponents)
+}
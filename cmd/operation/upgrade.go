@@ -0,0 +1,44 @@
+package operation
+
+import (
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/spf13/cobra"
+)
+
+// Upgrade declares `sonatina upgrade` command
+var Upgrade = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Updates base and plugins code to their last version",
+	Long: `Updates base and plugins code to the last version of their repositories.
+The upgrade is rejected and previous versions restored if the new code isn't
+compatible with the deployment, as declared on CTD manifests.`,
+	RunE: upgradeExecution,
+}
+
+func init() {
+	Upgrade.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+}
+
+func upgradeExecution(command *cobra.Command, args []string) error {
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	err = deploy.Upgrade()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Upgraded")
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/manager"
 
 	"github.com/spf13/cobra"
@@ -12,10 +13,11 @@ import (
 
 // CreatePlugin declares `sonatina create plugin` command
 var CreatePlugin = &cobra.Command{
-	Use:   "plugin",
-	Short: "Create or add a plugin to deployment",
-	Args:  cobra.ExactArgs(1),
-	RunE:  createPluginExecution,
+	Use:               "plugin",
+	Short:             "Create or add a plugin to deployment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteGlobalPlugins),
+	RunE:              createPluginExecution,
 }
 
 func init() {
@@ -23,6 +25,9 @@ func init() {
 	CreatePlugin.Flags().StringVarP(&repoURI, "repo-uri", "r", "", "plugin git repo uri")
 	CreatePlugin.Flags().StringVarP(&repoPath, "repo-path", "p", "", "plugin git repo path")
 	CreatePlugin.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component name")
+	CreatePlugin.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	CreatePlugin.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	CreatePlugin.Flags().BoolVar(&withDependencies, "with-dependencies", false, "add missing plugins the plugin depends on")
 }
 
 func createPluginExecution(command *cobra.Command, args []string) error {
@@ -39,13 +44,18 @@ func createPluginExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	if userComponent == "" {
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return err
+	}
+
+	if component == deployment.ComponentGlobal {
 		if repoURI == "" { // Only required if it's a global plugin
 			return errors.New("required flag(s) \"repo-uri\" not set")
 		}
-		err = deploy.CreatePluginGlobal(pluginName, repoURI, repoPath)
+		err = deploy.CreatePluginGlobal(pluginName, repoURI, repoPath, withDependencies)
 	} else {
-		err = deploy.CreatePluginUser(pluginName, userComponent)
+		err = deploy.CreatePluginComponent(pluginName, component, instance, withDependencies)
 	}
 	if err != nil {
 		return err
@@ -4,22 +4,31 @@ import (
 	"fmt"
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
 )
 
 // DeletePlugin declares `sonatina delete plugin` command
 var DeletePlugin = &cobra.Command{
-	Use:   "plugin",
-	Short: "Remove a specified plugin from deployment",
-	Args:  cobra.ExactArgs(1),
-	RunE:  deletePluginExecution,
+	Use:               "plugin",
+	Short:             "Remove a specified plugin from deployment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompletePlugins),
+	RunE:              deletePluginExecution,
 }
 
 func init() {
 	DeletePlugin.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	DeletePlugin.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component name")
+	DeletePlugin.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	DeletePlugin.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	DeletePlugin.Flags().BoolVarP(&force, "force", "f", false, "remove the global plugin from the user components that use it too")
+	DeletePlugin.Flags().BoolVar(&destroy, "destroy", false, "destroy the plugin resources of the components it's removed from before removing it")
+	DeletePlugin.Flags().StringVarP(&message, "message", "m", "", "commit message for the destroy operation")
 }
 
 func deletePluginExecution(command *cobra.Command, args []string) error {
@@ -36,10 +45,35 @@ func deletePluginExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	if userComponent == "" {
-		err = deploy.DeletePluginGlobal(pluginName)
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return err
+	}
+
+	// check the plugin can be removed before destroying any of its resources
+	if component == deployment.ComponentGlobal {
+		err = deploy.CanDeletePluginGlobal(pluginName, force)
+	} else {
+		err = deploy.CanDeletePluginComponent(pluginName, component, instance)
+	}
+	if err != nil {
+		return err
+	}
+
+	if component == deployment.ComponentGlobal {
+		if force {
+			err = destroyPluginGlobalResources(deploy, pluginName)
+			if err != nil {
+				return err
+			}
+		}
+		err = deploy.DeletePluginGlobal(pluginName, force)
 	} else {
-		err = deploy.DeletePluginUser(pluginName, userComponent)
+		err = destroyPluginComponentResources(deploy, pluginName, component, instance)
+		if err != nil {
+			return err
+		}
+		err = deploy.DeletePluginComponent(pluginName, component, instance)
 	}
 	if err != nil {
 		return err
@@ -48,3 +82,75 @@ func deletePluginExecution(command *cobra.Command, args []string) error {
 	fmt.Println("Deleted")
 	return nil
 }
+
+// destroyPluginGlobalResources checks the resources managed by a global plugin on the
+// component instances that use it, that is removed from them with --force.
+func destroyPluginGlobalResources(deploy deployment.Deployment, pluginName string) error {
+	components, err := deploy.ListComponentTypes()
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		if component == deployment.ComponentGlobal {
+			continue
+		}
+
+		instances, err := deploy.ListComponents(component)
+		if err != nil {
+			return err
+		}
+
+		for _, instance := range instances {
+			plugins, err := deploy.ListPluginsComponent(component, instance)
+			if err != nil {
+				return err
+			}
+
+			for _, plugin := range plugins {
+				if plugin != pluginName {
+					continue
+				}
+				err = destroyPluginComponentResources(deploy, pluginName, component, instance)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// destroyPluginComponentResources checks if the plugin manages resources on the
+// component, that would be destroyed on next apply after removing it. They're
+// destroyed if --destroy is set, otherwise an error is returned.
+func destroyPluginComponentResources(deploy deployment.Deployment, pluginName string, component string, instance string) error {
+	resources, err := deploy.ListPluginResourcesComponent(pluginName, component, instance)
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+
+	if !destroy {
+		return errors.Errorf("plugin %s manages %d resources on %s component %s, use --destroy to destroy them before removing it",
+			pluginName, len(resources), component, instance)
+	}
+
+	terraform, err := common.InitializeTerraform(deploy)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := common.Context()
+	defer cancel()
+
+	commitMessage := message
+	if commitMessage == "" {
+		commitMessage = fmt.Sprintf("destroy plugin %s of %s component %s", pluginName, component, instance)
+	}
+
+	return workflow.Destroy(terraform, deploy).RunComponentTargets(ctx, commitMessage, component, instance, resources)
+}
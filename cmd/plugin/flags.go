@@ -6,3 +6,9 @@ var repoURI string
 var repoPath string
 var deployName string
 var userComponent string
+var componentInstance string
+var componentType string
+var withDependencies bool
+var force bool
+var destroy bool
+var message string
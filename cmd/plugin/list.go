@@ -17,9 +17,16 @@ var ListPlugins = &cobra.Command{
 	RunE:  listPluginsExecution,
 }
 
+// pluginInfo is the structured output of `sonatina list plugins`
+type pluginInfo struct {
+	Name string `json:"name"`
+}
+
 func init() {
 	ListPlugins.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
 	ListPlugins.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	ListPlugins.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	ListPlugins.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
 }
 
 func listPluginsExecution(command *cobra.Command, args []string) error {
@@ -36,25 +43,30 @@ func listPluginsExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	if userComponent == "" {
-		plugins, err = deploy.ListPluginsGlobal()
-		if err != nil {
-			return err
-		}
-	} else {
-		plugins, err = deploy.ListPluginsUser(userComponent)
-		if err != nil {
-			return err
-		}
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintln(os.Stdout, "PLUGINS:")
+	plugins, err = deploy.ListPluginsComponent(component, instance)
+	if err != nil {
+		return err
+	}
+
+	result := []pluginInfo{}
 	for _, element := range plugins {
-		_, err = fmt.Fprintln(os.Stdout, element)
-		if err != nil {
-			return err
-		}
+		result = append(result, pluginInfo{Name: element})
 	}
 
-	return nil
+	return common.PrintOutput(result, func() error {
+		fmt.Fprintln(os.Stdout, "PLUGINS:")
+		for _, element := range plugins {
+			_, err = fmt.Fprintln(os.Stdout, element)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
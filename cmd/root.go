@@ -28,12 +28,19 @@ type stackTracer interface {
 var rootCmd = &cobra.Command{
 	Use:   "sonatina",
 	Short: "A terraform based framework to work in an opinionated way.",
+	PersistentPreRunE: func(command *cobra.Command, args []string) error {
+		return common.ValidateOutputFormat()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	if exitErr, ok := err.(common.ExitError); ok {
+		logrus.Infof("exit with code %d: %s", exitErr.Code, exitErr.Message)
+		os.Exit(exitErr.Code)
+	}
 	if err != nil {
 		st, ok := err.(stackTracer)
 		if ok {
@@ -51,22 +58,38 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().DurationVar(&common.Timeout, "timeout", 0, "maximum execution time, terraform is interrupted when exceeded (e.g. 30m)")
+	rootCmd.PersistentFlags().StringVarP(&common.OutputFormat, "output", "o", common.OutputTable, "output format of list, get and show commands: table, json or yaml")
 	rootCmd.SilenceUsage = true
 
 	// Register subcommands
 	rootCmd.AddCommand(operation.Apply)
 	rootCmd.AddCommand(operation.Clone)
+	rootCmd.AddCommand(operation.Completion)
+	rootCmd.AddCommand(operation.Copy)
 	rootCmd.AddCommand(operation.Create)
 	rootCmd.AddCommand(operation.Delete)
 	rootCmd.AddCommand(operation.Destroy)
+	rootCmd.AddCommand(operation.Doctor)
+	rootCmd.AddCommand(operation.Drift)
 	rootCmd.AddCommand(operation.Edit)
 	rootCmd.AddCommand(operation.Get)
 	rootCmd.AddCommand(operation.Init)
+	rootCmd.AddCommand(operation.Label)
 	rootCmd.AddCommand(operation.List)
+	rootCmd.AddCommand(operation.Mirror)
+	rootCmd.AddCommand(operation.Output)
 	rootCmd.AddCommand(operation.Refresh)
+	rootCmd.AddCommand(operation.Rename)
 	rootCmd.AddCommand(operation.Set)
 	rootCmd.AddCommand(operation.Show)
+	rootCmd.AddCommand(operation.Status)
+	rootCmd.AddCommand(operation.State)
+	rootCmd.AddCommand(operation.Sync)
+	rootCmd.AddCommand(operation.Upgrade)
 	rootCmd.AddCommand(operation.Use)
+
+	common.RegisterFlagCompletions(rootCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,8 @@
+package state
+
+//To define flags
+var deployName string
+var userComponent string
+var componentInstance string
+var componentType string
+var message string
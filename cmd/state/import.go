@@ -0,0 +1,38 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// ImportState declares `sonatina state import` command
+var ImportState = &cobra.Command{
+	Use:   "import ADDRESS ID",
+	Short: "Import existing infrastructure into the component state",
+	Args:  cobra.ExactArgs(2),
+	RunE:  importStateExecution,
+}
+
+func init() {
+	ImportState.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	ImportState.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	ImportState.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	ImportState.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	ImportState.Flags().StringVarP(&message, "message", "m", "", "commit message")
+}
+
+func importStateExecution(command *cobra.Command, args []string) error {
+	address := args[0]
+	id := args[1]
+
+	if message == "" {
+		message = fmt.Sprintf("state import %s %s", address, id)
+	}
+
+	return runState(func(ctx context.Context, state *workflow.StateWorkflow, component string, instance string) error {
+		return state.Import(ctx, message, component, instance, address, id)
+	})
+}
@@ -0,0 +1,28 @@
+package state
+
+import (
+	"context"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// ListState declares `sonatina state list` command
+var ListState = &cobra.Command{
+	Use:   "list",
+	Short: "List resources in the component state",
+	Args:  cobra.NoArgs,
+	RunE:  listStateExecution,
+}
+
+func init() {
+	ListState.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	ListState.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	ListState.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	ListState.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+}
+
+func listStateExecution(command *cobra.Command, args []string) error {
+	return runState(func(ctx context.Context, state *workflow.StateWorkflow, component string, instance string) error {
+		return state.List(ctx, component, instance)
+	})
+}
@@ -0,0 +1,38 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// MoveState declares `sonatina state mv` command
+var MoveState = &cobra.Command{
+	Use:   "mv SOURCE DESTINATION",
+	Short: "Move an item in the component state",
+	Args:  cobra.ExactArgs(2),
+	RunE:  moveStateExecution,
+}
+
+func init() {
+	MoveState.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	MoveState.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	MoveState.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	MoveState.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	MoveState.Flags().StringVarP(&message, "message", "m", "", "commit message")
+}
+
+func moveStateExecution(command *cobra.Command, args []string) error {
+	source := args[0]
+	destination := args[1]
+
+	if message == "" {
+		message = fmt.Sprintf("state mv %s %s", source, destination)
+	}
+
+	return runState(func(ctx context.Context, state *workflow.StateWorkflow, component string, instance string) error {
+		return state.Move(ctx, message, component, instance, source, destination)
+	})
+}
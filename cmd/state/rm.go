@@ -0,0 +1,38 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// RemoveState declares `sonatina state rm` command
+var RemoveState = &cobra.Command{
+	Use:   "rm ADDRESS...",
+	Short: "Remove items from the component state",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  removeStateExecution,
+}
+
+func init() {
+	RemoveState.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	RemoveState.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	RemoveState.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	RemoveState.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+	RemoveState.Flags().StringVarP(&message, "message", "m", "", "commit message")
+}
+
+func removeStateExecution(command *cobra.Command, args []string) error {
+	addresses := args
+
+	if message == "" {
+		message = fmt.Sprintf("state rm %s", strings.Join(addresses, " "))
+	}
+
+	return runState(func(ctx context.Context, state *workflow.StateWorkflow, component string, instance string) error {
+		return state.Remove(ctx, message, component, instance, addresses)
+	})
+}
@@ -0,0 +1,30 @@
+package state
+
+import (
+	"context"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/spf13/cobra"
+)
+
+// ShowState declares `sonatina state show` command
+var ShowState = &cobra.Command{
+	Use:   "show ADDRESS",
+	Short: "Show a resource in the component state",
+	Args:  cobra.ExactArgs(1),
+	RunE:  showStateExecution,
+}
+
+func init() {
+	ShowState.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	ShowState.Flags().StringVarP(&userComponent, "user-component", "c", "", "user component")
+	ShowState.Flags().StringVar(&componentInstance, "component", "", "component name, of the type set with --component-type or user")
+	ShowState.Flags().StringVarP(&componentType, "component-type", "t", "", "component type declared by the base CTD")
+}
+
+func showStateExecution(command *cobra.Command, args []string) error {
+	address := args[0]
+
+	return runState(func(ctx context.Context, state *workflow.StateWorkflow, component string, instance string) error {
+		return state.Show(ctx, component, instance, address)
+	})
+}
@@ -0,0 +1,42 @@
+package state
+
+import (
+	"context"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/workflow"
+)
+
+// stateOperation runs a state workflow operation over a component instance
+type stateOperation func(ctx context.Context, state *workflow.StateWorkflow, component string, instance string) error
+
+// runState runs a state operation over the deployment and component selected with
+// the command flags
+func runState(operation stateOperation) error {
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	component, instance, err := common.ResolveComponent(componentType, componentInstance, userComponent)
+	if err != nil {
+		return err
+	}
+
+	terraform, err := common.InitializeTerraform(deploy)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := common.Context()
+	defer cancel()
+
+	return operation(ctx, workflow.State(terraform, deploy), component, instance)
+}
@@ -0,0 +1,49 @@
+package usercomponent
+
+import (
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+
+	"github.com/spf13/cobra"
+)
+
+// CopyUsercomponent declares `sonatina copy usercomponent` command
+var CopyUsercomponent = &cobra.Command{
+	Use:   "usercomponent SRC DST",
+	Short: "Create an user component copying an existing one",
+	Long: `Create an user component with the same plugins, flavour and config variables
+than an existing one. The new user component starts with empty state.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteUsercomponents),
+	RunE:              copyUsercomponentExecution,
+}
+
+func init() {
+	CopyUsercomponent.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+}
+
+func copyUsercomponentExecution(command *cobra.Command, args []string) error {
+	srcUsercomponentName := args[0]
+	dstUsercomponentName := args[1]
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	err = deploy.CopyUsercomponent(srcUsercomponentName, dstUsercomponentName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Copied")
+	return nil
+}
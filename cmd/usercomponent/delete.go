@@ -5,20 +5,25 @@ import (
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
 	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
 )
 
 // CreateUsercomponent declares `sonatina create usercomponent` command
 var DeleteUsercomponent = &cobra.Command{
-	Use:   "usercomponent",
-	Short: "Remove an usercomponent from deployment",
-	Args:  cobra.ExactArgs(1),
-	RunE:  deleteUsercomponentExecution,
+	Use:               "usercomponent",
+	Short:             "Remove an usercomponent from deployment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteUsercomponents),
+	RunE:              deleteUsercomponentExecution,
 }
 
 func init() {
 	DeleteUsercomponent.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	DeleteUsercomponent.Flags().BoolVar(&destroy, "destroy", false, "destroy the user component resources before deleting it")
+	DeleteUsercomponent.Flags().StringVarP(&message, "message", "m", "", "commit message for the destroy operation")
 }
 
 func deleteUsercomponentExecution(command *cobra.Command, args []string) error {
@@ -35,6 +40,35 @@ func deleteUsercomponentExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
+	resources, err := deploy.ListManagedResourcesUser(usercomponentName)
+	if err != nil {
+		return err
+	}
+
+	if len(resources) > 0 {
+		if !destroy {
+			return errors.Errorf("user component %s manages %d resources, use --destroy to destroy them before deleting it",
+				usercomponentName, len(resources))
+		}
+
+		terraform, err := common.InitializeTerraform(deploy)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := common.Context()
+		defer cancel()
+
+		if message == "" {
+			message = fmt.Sprintf("destroy user component %s", usercomponentName)
+		}
+
+		err = workflow.Destroy(terraform, deploy).RunUser(ctx, message, usercomponentName)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = deploy.DeleteUsercomponent(usercomponentName)
 	if err != nil {
 		return err
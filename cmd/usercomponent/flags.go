@@ -2,3 +2,9 @@ package usercomponent
 
 //To define flags
 var deployName string
+var destroy bool
+var message string
+var file string
+var dryRun bool
+var apply bool
+var selector string
@@ -0,0 +1,84 @@
+package usercomponent
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+
+	"github.com/spf13/cobra"
+)
+
+// LabelUsercomponent declares `sonatina label usercomponent` command
+var LabelUsercomponent = &cobra.Command{
+	Use:   "usercomponent NAME [KEY=VALUE ...] [KEY- ...]",
+	Short: "Add, change or remove labels of an user component",
+	Long: `Add, change or remove labels of an user component. Labels are key=value
+pairs used to select user components with --selector. A key followed by '-'
+removes the label. Without labels, the current ones are printed.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteUsercomponents),
+	RunE:              labelUsercomponentExecution,
+}
+
+func init() {
+	LabelUsercomponent.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+}
+
+func labelUsercomponentExecution(command *cobra.Command, args []string) error {
+	usercomponentName := args[0]
+
+	labels, remove, err := deployment.ParseLabels(args[1:])
+	if err != nil {
+		return err
+	}
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	if len(labels) == 0 && len(remove) == 0 {
+		current, err := deploy.GetLabelsUser(usercomponentName)
+		if err != nil {
+			return err
+		}
+
+		if current == nil {
+			current = map[string]string{}
+		}
+		return common.PrintOutput(current, func() error {
+			for _, label := range formatLabels(current) {
+				fmt.Println(label)
+			}
+			return nil
+		})
+	}
+
+	err = deploy.SetLabelsUser(usercomponentName, labels, remove)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Labeled")
+	return nil
+}
+
+// formatLabels returns labels as key=value strings ordered by key
+func formatLabels(labels map[string]string) []string {
+	formatted := []string{}
+	for key, value := range labels {
+		formatted = append(formatted, key+"="+value)
+	}
+	sort.Strings(formatted)
+
+	return formatted
+}
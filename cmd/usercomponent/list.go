@@ -3,8 +3,10 @@ package usercomponent
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/manager"
 
 	"github.com/spf13/cobra"
@@ -17,8 +19,17 @@ var ListUsercomponents = &cobra.Command{
 	RunE:  listUsercomponentsExecution,
 }
 
+// usercomponentInfo is the structured output of `sonatina list usercomponents`
+type usercomponentInfo struct {
+	Name    string            `json:"name"`
+	Flavour string            `json:"flavour"`
+	Plugins []string          `json:"plugins"`
+	Labels  map[string]string `json:"labels"`
+}
+
 func init() {
 	ListUsercomponents.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	ListUsercomponents.Flags().StringVarP(&selector, "selector", "l", "", "label selector, like team=data,tier!=prod")
 }
 
 func listUsercomponentsExecution(command *cobra.Command, args []string) error {
@@ -33,18 +44,53 @@ func listUsercomponentsExecution(command *cobra.Command, args []string) error {
 		return err
 	}
 
-	list, err := deploy.ListUsercomponents()
+	s, err := deployment.ParseSelector(selector)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(os.Stdout, "USER COMPONENTS:")
+	list, err := deploy.ListUsercomponentsWithSelector(s)
+	if err != nil {
+		return err
+	}
+
+	usercomponents := []usercomponentInfo{}
 	for _, element := range list {
-		_, err = fmt.Fprintln(os.Stdout, element)
+		info := usercomponentInfo{Name: element}
+
+		info.Flavour, err = deploy.GetFlavourUser(element)
+		if err != nil {
+			return err
+		}
+		info.Plugins, err = deploy.ListPluginsUser(element)
 		if err != nil {
 			return err
 		}
+		info.Labels, err = deploy.GetLabelsUser(element)
+		if err != nil {
+			return err
+		}
+		if info.Labels == nil {
+			info.Labels = map[string]string{}
+		}
+
+		usercomponents = append(usercomponents, info)
 	}
 
-	return nil
+	return common.PrintOutput(usercomponents, func() error {
+		fmt.Fprintln(os.Stdout, "USER COMPONENTS:")
+		for _, info := range usercomponents {
+			element := info.Name
+			if len(info.Labels) > 0 {
+				element = element + "\t" + strings.Join(formatLabels(info.Labels), ",")
+			}
+
+			_, err = fmt.Fprintln(os.Stdout, element)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
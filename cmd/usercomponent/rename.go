@@ -0,0 +1,59 @@
+package usercomponent
+
+import (
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/manager"
+
+	"github.com/spf13/cobra"
+)
+
+// RenameUsercomponent declares `sonatina rename usercomponent` command
+var RenameUsercomponent = &cobra.Command{
+	Use:   "usercomponent OLD NEW",
+	Short: "Change the name of an user component",
+	Long: `Change the name of an user component, moving its metadata, variables and
+state. Changes are committed and pushed to the storage repository.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: common.CompleteFirstArg(common.CompleteUsercomponents),
+	RunE:              renameUsercomponentExecution,
+}
+
+func init() {
+	RenameUsercomponent.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	RenameUsercomponent.Flags().StringVarP(&message, "message", "m", "", "commit message")
+}
+
+func renameUsercomponentExecution(command *cobra.Command, args []string) error {
+	usercomponentName := args[0]
+	newUsercomponentName := args[1]
+
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	err = deploy.RenameUsercomponent(usercomponentName, newUsercomponentName)
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("rename user component %s to %s", usercomponentName, newUsercomponentName)
+	}
+
+	err = deploy.Push(message)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Renamed")
+	return nil
+}
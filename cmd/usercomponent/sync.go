@@ -0,0 +1,191 @@
+package usercomponent
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/arodriguezdlc/sonatina/cmd/common"
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/manager"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+	"github.com/arodriguezdlc/sonatina/workflow"
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+)
+
+// SyncUsercomponents declares `sonatina sync usercomponents` command
+var SyncUsercomponents = &cobra.Command{
+	Use:   "usercomponents",
+	Short: "Create, update and delete user components to match a file",
+	Long: `Create, update and delete user components to match the ones declared on a
+yaml file. The plan of changes is printed before applying them. Example:
+
+  usercomponents:
+    alice:
+      flavour: large
+      config:
+        instance_type: t3.small
+      plugins:
+        monitoring:
+          retention_days: 30
+    bob: {}
+
+User components not declared on the file are deleted.`,
+	Args: cobra.NoArgs,
+	RunE: syncUsercomponentsExecution,
+}
+
+func init() {
+	SyncUsercomponents.Flags().StringVarP(&deployName, "deployment", "d", "", "deployment name")
+	SyncUsercomponents.Flags().StringVarP(&file, "file", "f", "", "file declaring the user components")
+	SyncUsercomponents.Flags().BoolVar(&dryRun, "dry-run", false, "print the plan of changes without applying them")
+	SyncUsercomponents.Flags().BoolVar(&apply, "apply", false, "apply the created and updated user components")
+	SyncUsercomponents.Flags().BoolVar(&destroy, "destroy", false, "destroy the resources of the deleted user components and removed plugins")
+	SyncUsercomponents.Flags().StringVarP(&message, "message", "m", "", "commit message")
+	SyncUsercomponents.MarkFlagRequired("file")
+}
+
+func syncUsercomponentsExecution(command *cobra.Command, args []string) error {
+	deployName, err := common.GetCurrentDeployment(deployName)
+	if err != nil {
+		return err
+	}
+
+	m := manager.GetManager()
+	deploy, err := m.Get(deployName)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := deployment.ReadSyncManifest(common.Fs, file)
+	if err != nil {
+		return err
+	}
+
+	// Changes are validated by the plan, before destroying any user component
+	changes, err := deploy.PlanSyncUsercomponents(manifest)
+	if err != nil {
+		return err
+	}
+
+	printSyncPlan(changes)
+	if len(changes) == 0 || dryRun {
+		return nil
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("sync user components from %s", filepath.Base(file))
+	}
+
+	// Resources are checked before changing anything
+	destroyUsers := []string{}
+	destroyTargets := map[string][]string{}
+	for _, change := range changes {
+		if change.Action != deployment.SyncDelete {
+			for _, plugin := range change.RemovedPlugins {
+				resources, err := deploy.ListPluginResourcesComponent(plugin, deployment.ComponentUser, change.User)
+				if err != nil {
+					return err
+				}
+				if len(resources) > 0 {
+					if !destroy {
+						return errors.Errorf("plugin %s manages %d resources on user component %s, use --destroy to destroy them before removing it",
+							plugin, len(resources), change.User)
+					}
+					destroyTargets[change.User] = append(destroyTargets[change.User], resources...)
+				}
+			}
+			continue
+		}
+
+		resources, err := deploy.ListManagedResourcesUser(change.User)
+		if err != nil {
+			return err
+		}
+		if len(resources) > 0 {
+			if !destroy {
+				return errors.Errorf("user component %s manages %d resources, use --destroy to destroy them before deleting it",
+					change.User, len(resources))
+			}
+			destroyUsers = append(destroyUsers, change.User)
+		}
+	}
+
+	var terraform *terraformcli.Terraform
+	if len(destroyUsers) > 0 || len(destroyTargets) > 0 || apply {
+		terraform, err = common.InitializeTerraform(deploy)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := common.Context()
+	defer cancel()
+
+	for _, user := range destroyUsers {
+		err = workflow.Destroy(terraform, deploy).RunUser(ctx, message, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, change := range changes {
+		targets := destroyTargets[change.User]
+		if len(targets) == 0 {
+			continue
+		}
+
+		err = workflow.Destroy(terraform, deploy).RunUserTargets(ctx, message, change.User, targets)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = deploy.SyncUsercomponents(changes)
+	if err != nil {
+		return err
+	}
+
+	err = deploy.Push(message)
+	if err != nil {
+		return err
+	}
+
+	if apply {
+		for _, change := range changes {
+			if change.Action == deployment.SyncDelete {
+				continue
+			}
+
+			err = workflow.Apply(terraform, deploy).RunUser(ctx, message, change.User)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't apply user component %s", change.User)
+			}
+		}
+	}
+
+	fmt.Println("Synchronized")
+	return nil
+}
+
+func printSyncPlan(changes []deployment.SyncChange) {
+	if len(changes) == 0 {
+		fmt.Println("User components are up to date")
+		return
+	}
+
+	symbols := map[string]string{
+		deployment.SyncCreate: "+",
+		deployment.SyncUpdate: "~",
+		deployment.SyncDelete: "-",
+	}
+
+	for _, change := range changes {
+		fmt.Printf("%s %s user component %s\n", symbols[change.Action], change.Action, change.User)
+		for _, detail := range change.Details {
+			fmt.Printf("    %s\n", detail)
+		}
+	}
+	fmt.Println()
+}
@@ -10,7 +10,6 @@ func init() {
 	setDefaultConfig()
 	setEnvVariables()
 	setConfigFile()
-	viper.Set("BinaryArch", "darwin_amd64") // TODO: multiarch
 }
 
 func setDefaultConfig() {
@@ -23,6 +22,12 @@ func setDefaultConfig() {
 	viper.SetDefault("TestFilesystem", false)
 	viper.SetDefault("TerraformPath", "~/.sonatina/terraform")
 	viper.SetDefault("DefaultTerraformVersion", "0.13.5")
+	viper.SetDefault("DefaultEngine", "terraform")
+	viper.SetDefault("TerraformBinary", "")
+	viper.SetDefault("TerraformSigningKey", "")
+	viper.SetDefault("BinaryArch", "") // Detected from host if empty
+	viper.SetDefault("TerraformMirror", "")
+	viper.SetDefault("ProviderMirror", "")
 	viper.SetDefault("DefaultFlavour", "default")
 	viper.SetDefault("Editor", "vi")
 }
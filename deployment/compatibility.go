@@ -0,0 +1,134 @@
+package deployment
+
+import (
+	"sort"
+
+	"github.com/arodriguezdlc/sonatina/utils"
+	"github.com/pkg/errors"
+)
+
+// CheckCompatibility validates the base and plugin manifests against the
+// deployment terraform version and flavours, and plugins against the base version.
+func (d *DeploymentImpl) CheckCompatibility() error {
+	base, err := d.Base.Manifest()
+	if err != nil {
+		return err
+	}
+
+	err = d.checkBaseCompatibility(base)
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range d.Plugins {
+		err = d.checkPluginCompatibility(plugin, base)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DeploymentImpl) checkBaseCompatibility(base *Manifest) error {
+	err := checkTerraformVersion(base, "base", d.TerraformVersion())
+	if err != nil {
+		return err
+	}
+
+	if !base.supportsFlavour(d.Vars.Metadata.Flavour) {
+		return errors.Errorf("base doesn't support flavour %s, supported flavours are %v",
+			d.Vars.Metadata.Flavour, base.Flavours)
+	}
+
+	return d.checkComponentFlavours(base, "base", "")
+}
+
+func (d *DeploymentImpl) checkPluginCompatibility(plugin *CTD, base *Manifest) error {
+	manifest, err := plugin.Manifest()
+	if err != nil {
+		return err
+	}
+
+	description := plugin.description()
+	err = checkTerraformVersion(manifest, description, d.TerraformVersion())
+	if err != nil {
+		return err
+	}
+
+	if manifest.Base != "" {
+		if base.Version == "" {
+			return errors.Errorf("%s requires base version %s, but base doesn't declare its version",
+				description, manifest.Base)
+		}
+
+		ok, err := utils.CheckVersionConstraint(base.Version, manifest.Base)
+		if err != nil {
+			return errors.Wrapf(err, "invalid base version constraint on %s", description)
+		}
+		if !ok {
+			return errors.Errorf("%s requires base version %s, but base version is %s",
+				description, manifest.Base, base.Version)
+		}
+	}
+
+	if !manifest.supportsFlavour(d.Vars.Metadata.Flavour) {
+		return errors.Errorf("%s doesn't support flavour %s, supported flavours are %v",
+			description, d.Vars.Metadata.Flavour, manifest.Flavours)
+	}
+
+	return d.checkComponentFlavours(manifest, description, plugin.Name)
+}
+
+// checkComponentFlavours returns an error if the manifest doesn't support the flavour
+// of any instance of the component types other than global. If plugin is set, only
+// the instances using that plugin are checked.
+func (d *DeploymentImpl) checkComponentFlavours(manifest *Manifest, description string, plugin string) error {
+	components := []string{}
+	for component := range d.Vars.Metadata.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	components = append([]string{ComponentUser}, components...)
+
+	for _, component := range components {
+		instances := d.Vars.Metadata.instances(component)
+
+		names := make([]string, 0, len(instances))
+		for instance := range instances {
+			names = append(names, instance)
+		}
+		sort.Strings(names)
+
+		for _, instance := range names {
+			if plugin != "" && !d.Vars.Metadata.componentPluginExists(plugin, component, instance) {
+				continue
+			}
+
+			flavour := instances[instance].Flavour
+			if !manifest.supportsFlavour(flavour) {
+				return errors.Errorf("%s doesn't support flavour %s of %s, supported flavours are %v",
+					description, flavour, describeComponent(component, instance), manifest.Flavours)
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkTerraformVersion(manifest *Manifest, description string, version string) error {
+	if manifest.Terraform == "" {
+		return nil
+	}
+
+	ok, err := utils.CheckVersionConstraint(version, manifest.Terraform)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't check terraform version required by %s", description)
+	}
+	if !ok {
+		return errors.Errorf("%s requires terraform %s, but deployment uses %s",
+			description, manifest.Terraform, version)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package deployment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testCompatibilityWriteManifests(fs, "terraform: \">= 0.13.0\"\nbase: \"~> 1.2\"\nflavours: [small, large]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := testCompatibilityNewDeployment(fs, "0.13.5", "small")
+
+	err = deploy.CheckCompatibility()
+	if err != nil {
+		t.Errorf("Compatible deployment returned error: %v", err)
+	}
+}
+
+func TestCheckCompatibilityErrors(t *testing.T) {
+	tests := []struct {
+		plugin           string
+		terraformVersion string
+		flavour          string
+		expectedError    string
+	}{
+		{"terraform: \">= 0.14.0\"\n", "0.13.5", "small", "plugin plugin1 requires terraform >= 0.14.0, but deployment uses 0.13.5"},
+		{"base: \">= 2.0.0\"\n", "0.13.5", "small", "plugin plugin1 requires base version >= 2.0.0, but base version is 1.2.0"},
+		{"flavours: [large]\n", "0.13.5", "small", "plugin plugin1 doesn't support flavour small"},
+	}
+
+	for _, test := range tests {
+		fs := afero.NewMemMapFs()
+
+		err := testCompatibilityWriteManifests(fs, test.plugin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		deploy := testCompatibilityNewDeployment(fs, test.terraformVersion, test.flavour)
+
+		err = deploy.CheckCompatibility()
+		if err == nil || !strings.HasPrefix(err.Error(), test.expectedError) {
+			t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", test.expectedError, err)
+		}
+	}
+}
+
+func TestCheckCompatibilityComponentFlavours(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testCompatibilityWriteManifests(fs, "flavours: [small]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := testCompatibilityNewDeployment(fs, "0.13.5", "small")
+	deploy.Vars.Metadata.Components = map[string]map[string]userComponent{
+		"database": {
+			"main":    {Flavour: "small"},
+			"replica": {Flavour: "medium"},
+		},
+	}
+
+	expectedError := "base doesn't support flavour medium of database component replica"
+	err = deploy.CheckCompatibility()
+	if err == nil || !strings.HasPrefix(err.Error(), expectedError) {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expectedError, err)
+	}
+
+	// plugins only check the instances using them
+	deploy.Vars.Metadata.Components["database"]["replica"] = userComponent{Flavour: "large"}
+	deploy.Vars.Metadata.Components["database"]["main"] = userComponent{
+		Flavour: "large",
+		Plugins: []userPlugin{testNewUserPlugin("plugin1")},
+	}
+
+	expectedError = "plugin plugin1 doesn't support flavour large of database component main"
+	err = deploy.CheckCompatibility()
+	if err == nil || !strings.HasPrefix(err.Error(), expectedError) {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expectedError, err)
+	}
+}
+
+func testCompatibilityWriteManifests(fs afero.Fs, plugin string) error {
+	base := "name: base\nversion: 1.2.0\nterraform: \">= 0.12.0, < 0.15.0\"\nflavours: [small, large]\n"
+
+	err := afero.WriteFile(fs, "/deployment/code/base/sonatina.yaml", []byte(base), 0644)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, "/deployment/code/plugins/plugin1/sonatina.yaml", []byte(plugin), 0644)
+}
+
+func testCompatibilityNewDeployment(fs afero.Fs, terraformVersion string, flavour string) *DeploymentImpl {
+	return &DeploymentImpl{
+		Name: "deployment",
+		fs:   fs,
+		path: "/deployment",
+
+		Base:    NewCTD(fs, "/deployment/code/base", "", "", ""),
+		Plugins: []*CTD{NewCTD(fs, "/deployment/code/plugins/plugin1", "plugin1", "", "")},
+		Vars: &Vars{
+			Metadata: &Metadata{
+				TerraformVersion: terraformVersion,
+				Flavour:          flavour,
+				UserComponents:   map[string]userComponent{},
+			},
+		},
+	}
+}
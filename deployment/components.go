@@ -0,0 +1,334 @@
+package deployment
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// Default component types. The global component has a single instance, while user
+// components and the component types declared by the base CTD have named instances.
+const (
+	ComponentGlobal string = "global"
+	ComponentUser   string = "user"
+)
+
+var componentTypeRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ComponentType declares on the base CTD manifest a component type, besides the
+// global and user ones. Each type has its main directory, main/<name>, and its
+// VTD subtree: config/<name>.tfvars, static/<name>.tfvars and flavour/<name>/.
+type ComponentType struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+func (c *ComponentType) validate() error {
+	if !componentTypeRegexp.MatchString(c.Name) {
+		return errors.Errorf("invalid component type name %q", c.Name)
+	}
+	if c.Name == ComponentGlobal || c.Name == ComponentUser {
+		return errors.Errorf("component type %s is reserved", c.Name)
+	}
+	return nil
+}
+
+// describeComponent returns a name to identify a component instance on messages
+func describeComponent(component string, instance string) string {
+	if component == ComponentGlobal {
+		return "global component"
+	}
+	return fmt.Sprintf("%s component %s", component, instance)
+}
+
+// componentSubpath returns the relative path used for a component instance on the
+// variables, state and workdir directory trees
+func componentSubpath(component string, instance string) string {
+	if component == ComponentGlobal {
+		return ComponentGlobal
+	}
+	return filepath.Join(component, instance)
+}
+
+// ListComponentTypes returns the global and user component types followed by
+// the ones declared on the base CTD manifest
+func (d *DeploymentImpl) ListComponentTypes() ([]string, error) {
+	manifest, err := d.Base.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	types := []string{ComponentGlobal, ComponentUser}
+	for _, componentType := range manifest.Components {
+		types = append(types, componentType.Name)
+	}
+
+	return types, nil
+}
+
+// checkComponentType returns an error if the component type isn't declared or
+// doesn't have named instances
+func (d *DeploymentImpl) checkComponentType(component string) error {
+	if component == ComponentGlobal {
+		return errors.New("global component doesn't have instances")
+	}
+
+	types, err := d.ListComponentTypes()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range types {
+		if t == component {
+			return nil
+		}
+	}
+	return errors.Errorf("component type %s isn't declared on base manifest, must be one of %v", component, types[1:])
+}
+
+// CreateComponent creates a new instance of a component type for the deployment,
+// calling the respective methods con Vars and State objects
+func (d *DeploymentImpl) CreateComponent(component string, instance string) error {
+	err := d.checkComponentType(component)
+	if err != nil {
+		return err
+	}
+
+	err = d.Vars.CreateComponent(component, instance)
+	if err != nil {
+		return err
+	}
+
+	err = d.State.CreateComponent(component, instance)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteComponent deletes an instance of a component type for the deployment,
+// calling the respective methods con Vars and State objects
+func (d *DeploymentImpl) DeleteComponent(component string, instance string) error {
+	err := d.Vars.DeleteComponent(component, instance)
+	if err != nil {
+		return err
+	}
+
+	err = d.State.DeleteComponent(component, instance)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListComponents returns the sorted instance names of a component type
+func (d *DeploymentImpl) ListComponents(component string) ([]string, error) {
+	err := d.checkComponentType(component)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Vars.Metadata.ListComponents(component)
+}
+
+// CreatePluginComponent adds a plugin to an instance of a component type. Plugin must
+// have been added to de global component. Plugins it depends on must have been added
+// to the instance before, unless withDependencies is set, that adds them automatically.
+func (d *DeploymentImpl) CreatePluginComponent(name string, component string, instance string, withDependencies bool) error {
+	plugin, err := d.getPluginByName(name)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := plugin.Manifest()
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range manifest.Dependencies {
+		if d.Vars.Metadata.componentPluginExists(dependency.Name, component, instance) {
+			continue
+		}
+
+		if !withDependencies {
+			return errors.Errorf("plugin %s depends on plugin %s, that must be added to %s first",
+				name, dependency.Name, describeComponent(component, instance))
+		}
+
+		err = d.CreatePluginComponent(dependency.Name, component, instance, withDependencies)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't add plugin %s required by %s", dependency.Name, name)
+		}
+	}
+
+	return d.Vars.Metadata.CreateComponentPlugin(name, component, instance)
+}
+
+// DeletePluginComponent removes the plugin from an instance of a component type. It
+// can't be removed while other plugins of the instance depend on it.
+func (d *DeploymentImpl) DeletePluginComponent(name string, component string, instance string) error {
+	err := d.CanDeletePluginComponent(name, component, instance)
+	if err != nil {
+		return err
+	}
+
+	return d.Vars.Metadata.DeleteComponentPlugin(name, component, instance)
+}
+
+// CanDeletePluginComponent returns an error if the plugin can't be removed from an
+// instance of a component type with DeletePluginComponent, without removing it
+func (d *DeploymentImpl) CanDeletePluginComponent(name string, component string, instance string) error {
+	err := d.Vars.Metadata.CheckDeleteComponentPlugin(name, component, instance)
+	if err != nil {
+		return err
+	}
+
+	plugins, err := d.Vars.Metadata.ListComponentPlugins(component, instance)
+	if err != nil {
+		return err
+	}
+
+	return d.checkDependents(name, plugins)
+}
+
+// ListPluginsComponent returns a list with the names of the plugins added to
+// an instance of a component type
+func (d *DeploymentImpl) ListPluginsComponent(component string, instance string) ([]string, error) {
+	if component == ComponentGlobal {
+		return d.ListPluginsGlobal()
+	}
+	return d.Vars.Metadata.ListComponentPlugins(component, instance)
+}
+
+// ListHooksComponent returns the hooks declared for the event by the base and the
+// plugins of a component instance, in execution order.
+func (d *DeploymentImpl) ListHooksComponent(event string, component string, instance string) ([]Hook, error) {
+	hooks, err := d.Base.ListHooks(event)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := d.componentPlugins(component, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, plugin := range plugins {
+		pluginHooks, err := plugin.ListHooks(event)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, pluginHooks...)
+	}
+
+	return hooks, nil
+}
+
+// GetFlavourComponent returns the name of the configured flavour for a component instance
+func (d *DeploymentImpl) GetFlavourComponent(component string, instance string) (string, error) {
+	if component == ComponentGlobal {
+		return d.GetFlavourGlobal()
+	}
+	return d.Vars.Metadata.GetComponentFlavour(component, instance)
+}
+
+// SetFlavourComponent configures the flavour for a component instance
+func (d *DeploymentImpl) SetFlavourComponent(flavour string, component string, instance string) error {
+	if component == ComponentGlobal {
+		return d.SetFlavourGlobal(flavour)
+	}
+	return d.Vars.Metadata.SetComponentFlavour(flavour, component, instance)
+}
+
+// GenerateWorkdirComponent combines deployment CTDs (main and plugins) to generate the
+// CTD of a component instance to be applied by terraform. Returns main path where
+// terraform must be executed.
+func (d *DeploymentImpl) GenerateWorkdirComponent(component string, instance string) (string, error) {
+	if component != ComponentGlobal {
+		err := d.Vars.Metadata.load()
+		if err != nil {
+			return "", err
+		}
+
+		err = d.Vars.Metadata.checkComponentExists(component, instance)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err := d.Workdir.Generate(component, instance)
+	if err != nil {
+		return "", err
+	}
+	return d.Workdir.mainPath(component, instance), nil
+}
+
+// SaveLockFileComponent copies the terraform dependency lock file from a component
+// instance workdir to the storage repository, to be committed with the variables.
+func (d *DeploymentImpl) SaveLockFileComponent(component string, instance string) error {
+	return d.Workdir.saveLockFile(d.Workdir.mainPath(component, instance), d.Vars.LockFilePath(component, instance))
+}
+
+// GenerateVariablesComponent reads the variable files from the VTDs for a component
+// instance, and copy them to the storage repository. Also returns a list with the
+// filepaths and the order that must be used when passing the variables to Terraform.
+func (d *DeploymentImpl) GenerateVariablesComponent(component string, instance string) ([]string, error) {
+	return d.Vars.Generate(component, instance)
+}
+
+// GetVariableFilepathComponent returns the path where is the variable file copied to the
+// storage repository for an specified kind (config, flavour or static), plugin and component
+// instance. Use empty string ("") on plugin parameter to obtain the base variable file.
+func (d *DeploymentImpl) GetVariableFilepathComponent(kind string, plugin string, component string, instance string) (string, error) {
+	return d.Vars.GetVariableFilepath(kind, plugin, component, instance)
+}
+
+// ReadVariableFileComponent returns the content of the variable file copied to the storage
+// repository for an specified kind (config, flavour or static), plugin and component instance.
+func (d *DeploymentImpl) ReadVariableFileComponent(kind string, plugin string, component string, instance string) (string, error) {
+	filepath, err := d.GetVariableFilepathComponent(kind, plugin, component, instance)
+	if err != nil {
+		return "", err
+	}
+
+	bytes, err := afero.ReadFile(d.fs, filepath)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't read file")
+	}
+
+	return string(bytes), nil
+}
+
+// StateFilePathComponent returns the terraform state file path of a component instance
+func (d *DeploymentImpl) StateFilePathComponent(component string, instance string) string {
+	return d.State.FilePath(component, instance)
+}
+
+// componentPlugins returns the plugin CTDs of a component instance, that are all
+// of them for the global component
+func (d *DeploymentImpl) componentPlugins(component string, instance string) ([]*CTD, error) {
+	if component == ComponentGlobal {
+		return d.Plugins, nil
+	}
+
+	pluginList, err := d.Vars.Metadata.listComponentPlugins(component, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := []*CTD{}
+	for _, pluginName := range pluginList {
+		plugin, err := d.getPluginByName(pluginName)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	return plugins, nil
+}
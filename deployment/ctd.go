@@ -18,6 +18,10 @@ type CTD struct {
 	RepoURL  string
 	RepoPath string
 
+	// Ref is the branch followed by the CTD on upgrades. If it's empty, the branch
+	// checked out on the repository is followed.
+	Ref string
+
 	main    *main
 	modules *modules
 	vtd     *VTD
@@ -61,18 +65,19 @@ func NewCTD(fs afero.Fs, path string, name string, repoURL string, repoPath stri
 
 // ListMainGlobalFiles returns all TF files from the global main folder
 func (ctd *CTD) ListMainGlobalFiles() ([]string, error) {
-	slice, err := afero.Glob(ctd.main.fs, filepath.Join(ctd.main.globalPath(), "/*.tf"))
-	if err != nil {
-		return slice, errors.Wrap(err, "cannot list main global files")
-	}
-	return slice, nil
+	return ctd.ListMainFiles(ComponentGlobal)
 }
 
 // ListMainUserFiles returns all TF files from the user main folder
 func (ctd *CTD) ListMainUserFiles() ([]string, error) {
-	slice, err := afero.Glob(ctd.main.fs, filepath.Join(ctd.main.userPath(), "/*.tf"))
+	return ctd.ListMainFiles(ComponentUser)
+}
+
+// ListMainFiles returns all TF files from the main folder of a component type
+func (ctd *CTD) ListMainFiles(component string) ([]string, error) {
+	slice, err := afero.Glob(ctd.main.fs, filepath.Join(ctd.main.componentPath(component), "/*.tf"))
 	if err != nil {
-		return slice, errors.Wrap(err, "cannot list main user files")
+		return slice, errors.Wrapf(err, "cannot list main %s files", component)
 	}
 	return slice, nil
 }
@@ -86,16 +91,58 @@ func (ctd *CTD) ListModules() ([]string, error) {
 	return slice, nil
 }
 
+// Manifest returns the CTD manifest. CTDs without manifest file return an empty one.
+func (ctd *CTD) Manifest() (*Manifest, error) {
+	return readManifest(ctd.fs, ctd.path)
+}
+
+// ListHooks returns the commands declared by the CTD for the specified hook event
+func (ctd *CTD) ListHooks(event string) ([]Hook, error) {
+	manifest, err := ctd.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := []Hook{}
+	for _, command := range manifest.Hooks[event] {
+		hooks = append(hooks, Hook{
+			CTD:              ctd.description(),
+			Path:             ctd.path,
+			Command:          command,
+			SensitiveOutputs: manifest.HookSensitiveOutputs,
+		})
+	}
+	return hooks, nil
+}
+
 // Clone executes a `git clone` command equivalent to get the CTD repository
 func (ctd *CTD) Clone() error {
 	return ctd.git.Clone(ctd.RepoURL)
 }
 
 // Pull executes a `git pull` command equivalent to update the CTD repository
-// with the last changes
+// with the last changes of its branch
 func (ctd *CTD) Pull() error {
-	ctd.git.Pull("origin", "master") // TODO: use a specified branch
-	return nil
+	ref := ctd.Ref
+	if ref == "" {
+		branch, err := ctd.git.Branch()
+		if err != nil {
+			return err
+		}
+		ref = branch
+	}
+
+	return ctd.git.Pull("origin", ref)
+}
+
+// Head returns the commit hash the CTD repository is pointing to
+func (ctd *CTD) Head() (string, error) {
+	return ctd.git.Head()
+}
+
+// Reset points the CTD repository to the specified commit, discarding any change
+func (ctd *CTD) Reset(commit string) error {
+	return ctd.git.ResetHard(commit)
 }
 
 // Checkout executes a `git checkout` command equivalent to point the CTD
@@ -105,10 +152,14 @@ func (ctd *CTD) Checkout() error {
 	return nil
 }
 
-func (m *main) globalPath() string {
-	return filepath.Join(m.path, "global")
+// description returns a name to identify the CTD on messages
+func (ctd *CTD) description() string {
+	if ctd.Name == "" {
+		return "base"
+	}
+	return "plugin " + ctd.Name
 }
 
-func (m *main) userPath() string {
-	return filepath.Join(m.path, "user")
+func (m *main) componentPath(component string) string {
+	return filepath.Join(m.path, component)
 }
@@ -1,9 +1,13 @@
 package deployment
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/arodriguezdlc/sonatina/gitw"
 	"github.com/arodriguezdlc/sonatina/utils"
 
 	"github.com/spf13/afero"
@@ -98,3 +102,81 @@ func testCTDFiles() []string {
 		"/modules/module2/file2.tf",
 	}
 }
+
+func TestPull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ctd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs := afero.NewOsFs()
+	origin, _ := gitw.NewCommand(fs, filepath.Join(dir, "origin"))
+
+	err = origin.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = testCommitFile(fs, origin, filepath.Join(dir, "origin", "main", "global", "main.tf"), "initial")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	branch, err := origin.Branch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctd := NewCTD(fs, filepath.Join(dir, "ctd"), "example", filepath.Join(dir, "origin"), "/")
+	err = ctd.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without ref the checked out branch is followed, otherwise the ref branch
+	for _, ref := range []string{"", branch} {
+		ctd.Ref = ref
+
+		err = testCommitFile(fs, origin, filepath.Join(dir, "origin", "main", "global", "main.tf"), "update "+ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ctd.Pull()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := origin.Head()
+		if err != nil {
+			t.Fatal(err)
+		}
+		obtained, err := ctd.Head()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, obtained) {
+			t.Errorf("Incorrect head with ref %q.\n\n Expected: %v\n\n Obtained: %v\n", ref, expected, obtained)
+		}
+	}
+}
+
+func testCommitFile(fs afero.Fs, git *gitw.Command, path string, content string) error {
+	err := fs.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = afero.WriteFile(fs, path, []byte(content), 0644)
+	if err != nil {
+		return err
+	}
+
+	err = git.AddGlob(".")
+	if err != nil {
+		return err
+	}
+
+	return git.Commit(content)
+}
@@ -0,0 +1,130 @@
+package deployment
+
+import (
+	"github.com/pkg/errors"
+)
+
+// sortPlugins returns the plugin names ordered so each plugin comes after the
+// plugins it depends on, keeping the current order otherwise. Dependencies not
+// included on names are ignored.
+func sortPlugins(names []string, dependencies map[string][]string) ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	included := map[string]bool{}
+	for _, name := range names {
+		included[name] = true
+	}
+
+	sorted := []string{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("circular plugin dependency %v", append(path, name))
+		}
+
+		state[name] = visiting
+		for _, dependency := range dependencies[name] {
+			if !included[dependency] {
+				continue
+			}
+			err := visit(dependency, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, name)
+		return nil
+	}
+
+	for _, name := range names {
+		err := visit(name, []string{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// pluginDependencies returns the dependencies declared by each global plugin
+func (d *DeploymentImpl) pluginDependencies() (map[string][]string, error) {
+	dependencies := map[string][]string{}
+	for _, plugin := range d.Plugins {
+		manifest, err := plugin.Manifest()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dependency := range manifest.Dependencies {
+			dependencies[plugin.Name] = append(dependencies[plugin.Name], dependency.Name)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// sortPluginsGlobal orders global plugins by their dependencies, both on metadata
+// and on the deployment plugin list.
+func (d *DeploymentImpl) sortPluginsGlobal() error {
+	dependencies, err := d.pluginDependencies()
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, plugin := range d.Plugins {
+		names = append(names, plugin.Name)
+	}
+
+	order, err := sortPlugins(names, dependencies)
+	if err != nil {
+		return err
+	}
+
+	plugins := []*CTD{}
+	for _, name := range order {
+		plugin, err := d.getPluginByName(name)
+		if err != nil {
+			return err
+		}
+		plugins = append(plugins, plugin)
+	}
+	d.Plugins = plugins
+
+	return d.Vars.Metadata.SortGlobalPlugins(order)
+}
+
+// checkDependents returns an error if any of the candidate plugins depends on
+// the specified plugin
+func (d *DeploymentImpl) checkDependents(name string, candidates []string) error {
+	dependencies, err := d.pluginDependencies()
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		for _, dependency := range dependencies[candidate] {
+			if dependency == name {
+				return errors.Errorf("plugin %s is required by plugin %s", name, candidate)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *DeploymentImpl) removePlugin(name string) {
+	for i, plugin := range d.Plugins {
+		if plugin.Name == name {
+			d.Plugins = append(d.Plugins[:i], d.Plugins[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestSortPlugins(t *testing.T) {
+	names := []string{"monitoring", "database", "network", "dns"}
+	dependencies := map[string][]string{
+		"monitoring": {"database", "network"},
+		"database":   {"network"},
+		"dns":        {"external"},
+	}
+
+	obtained, err := sortPlugins(names, dependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"network", "database", "monitoring", "dns"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect plugin order.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestSortPluginsWithCycle(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	dependencies := map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	}
+
+	_, err := sortPlugins(names, dependencies)
+	if err == nil {
+		t.Errorf("Circular dependencies must return an error")
+	}
+}
+
+func TestCheckDependents(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/deployment/code/plugins/database/sonatina.yaml", []byte("dependencies:\n  - name: network\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		path: "/deployment",
+		Plugins: []*CTD{
+			NewCTD(fs, "/deployment/code/plugins/network", "network", "", ""),
+			NewCTD(fs, "/deployment/code/plugins/database", "database", "", ""),
+		},
+	}
+
+	err = deploy.checkDependents("network", []string{"network", "database"})
+	expectedError := "plugin network is required by plugin database"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expectedError, err)
+	}
+
+	err = deploy.checkDependents("database", []string{"network", "database"})
+	if err != nil {
+		t.Errorf("Plugin without dependents returned error: %v", err)
+	}
+}
+
+func TestCanDeletePlugin(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/deployment/code/plugins/database/sonatina.yaml", []byte("dependencies:\n  - name: network\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := &Metadata{
+		fs:       fs,
+		filePath: "/deployment/variables/metadata.json",
+		Plugins:  []globalPlugin{{Name: "network"}, {Name: "database"}},
+		UserComponents: map[string]userComponent{
+			"user1": {Plugins: []userPlugin{{Name: "network"}, {Name: "database"}}},
+		},
+	}
+	err = metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		path: "/deployment",
+		Vars: &Vars{Metadata: metadata},
+		Plugins: []*CTD{
+			NewCTD(fs, "/deployment/code/plugins/network", "network", "", ""),
+			NewCTD(fs, "/deployment/code/plugins/database", "database", "", ""),
+		},
+	}
+
+	tests := []struct {
+		err           error
+		expectedError string
+	}{
+		{deploy.CanDeletePluginGlobal("network", true), "plugin network is required by plugin database"},
+		{deploy.CanDeletePluginGlobal("database", false), "global plugin database is used by user components [user1]"},
+		{deploy.CanDeletePluginGlobal("dns", true), "global plugin dns doesn't exist"},
+		{deploy.CanDeletePluginComponent("network", ComponentUser, "user1"), "plugin network is required by plugin database"},
+		{deploy.CanDeletePluginComponent("database", ComponentUser, "user2"), "user component user2 doesn't exist"},
+	}
+
+	for _, test := range tests {
+		if test.err == nil || test.err.Error() != test.expectedError {
+			t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", test.expectedError, test.err)
+		}
+	}
+
+	err = deploy.CanDeletePluginGlobal("database", true)
+	if err != nil {
+		t.Errorf("Plugin without dependents returned error: %v", err)
+	}
+	err = deploy.CanDeletePluginComponent("database", ComponentUser, "user1")
+	if err != nil {
+		t.Errorf("Plugin without dependents returned error: %v", err)
+	}
+}
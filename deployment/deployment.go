@@ -2,7 +2,9 @@ package deployment
 
 import (
 	"path/filepath"
+	"sort"
 
+	"github.com/arodriguezdlc/sonatina/utils"
 	"github.com/pkg/errors"
 
 	"github.com/sirupsen/logrus"
@@ -14,41 +16,88 @@ import (
 type Deployment interface {
 	CreateUsercomponent(user string) error
 	DeleteUsercomponent(user string) error
+	RenameUsercomponent(user string, newUser string) error
+	CopyUsercomponent(src string, dst string) error
 	ListUsercomponents() ([]string, error)
+	PlanSyncUsercomponents(manifest *SyncManifest) ([]SyncChange, error)
+	SyncUsercomponents(changes []SyncChange) error
 
-	CreatePluginGlobal(name string, repo string, repoPath string) error
-	DeletePluginGlobal(name string) error
+	GetLabelsUser(user string) (map[string]string, error)
+	SetLabelsUser(user string, labels map[string]string, remove []string) error
+	ListUsercomponentsWithSelector(selector Selector) ([]string, error)
+
+	ListComponentTypes() ([]string, error)
+	CreateComponent(component string, instance string) error
+	DeleteComponent(component string, instance string) error
+	ListComponents(component string) ([]string, error)
+
+	CreatePluginGlobal(name string, repo string, repoPath string, withDependencies bool) error
+	DeletePluginGlobal(name string, force bool) error
+	CanDeletePluginGlobal(name string, force bool) error
 	ListPluginsGlobal() ([]string, error)
 
-	CreatePluginUser(name string, user string) error
+	CreatePluginUser(name string, user string, withDependencies bool) error
 	DeletePluginUser(name string, user string) error
 	ListPluginsUser(user string) ([]string, error)
 
+	CreatePluginComponent(name string, component string, instance string, withDependencies bool) error
+	DeletePluginComponent(name string, component string, instance string) error
+	CanDeletePluginComponent(name string, component string, instance string) error
+	ListPluginsComponent(component string, instance string) ([]string, error)
+
 	GetFlavourGlobal() (string, error)
+	ListFlavours() ([]string, error)
 	SetFlavourGlobal(flavour string) error
 
 	GetFlavourUser(user string) (string, error)
 	SetFlavourUser(flavour string, user string) error
 
+	GetFlavourComponent(component string, instance string) (string, error)
+	SetFlavourComponent(flavour string, component string, instance string) error
+
 	GenerateWorkdirGlobal() (string, error)
 	GenerateWorkdirUser(user string) (string, error)
+	GenerateWorkdirComponent(component string, instance string) (string, error)
+
+	SaveLockFileGlobal() error
+	SaveLockFileUser(user string) error
+	SaveLockFileComponent(component string, instance string) error
 
 	GenerateVariablesGlobal() ([]string, error)
 	GenerateVariablesUser(user string) ([]string, error)
+	GenerateVariablesComponent(component string, instance string) ([]string, error)
 
 	GetVariableFilepath(kind string, plugin string, user string) (string, error)
 	ReadVariableFile(kind string, plugin string, user string) (string, error)
+	GetVariableFilepathComponent(kind string, plugin string, component string, instance string) (string, error)
+	ReadVariableFileComponent(kind string, plugin string, component string, instance string) (string, error)
 
 	Push(message string) error
 	Pull() error
+	Status(fetch bool) (*Status, error)
 
 	StateFilePathGlobal() string
 	StateFilePathUser(user string) string
+	StateFilePathComponent(component string, instance string) string
+	ListManagedResourcesUser(user string) ([]string, error)
+	ListManagedResourcesComponent(component string, instance string) ([]string, error)
+	ListPluginResourcesUser(name string, user string) ([]string, error)
+	ListPluginResourcesComponent(name string, component string, instance string) ([]string, error)
 
 	TerraformVersion() string
+	Engine() string
 	CodeRepoURL() string
 	CodeRepoPath() string
 
+	LogsPath() string
+
+	ListHooksGlobal(event string) ([]Hook, error)
+	ListHooksUser(event string, user string) ([]Hook, error)
+	ListHooksComponent(event string, component string, instance string) ([]Hook, error)
+
+	CheckCompatibility() error
+	Upgrade() error
+
 	Purge() error
 }
 
@@ -71,29 +120,58 @@ type DeploymentImpl struct {
 // CreateUsercomponent creates a new user component for the deployment,
 // calling the respective methods con Vars and State objects
 func (d *DeploymentImpl) CreateUsercomponent(user string) error {
-	err := d.Vars.CreateUsercomponent(user)
+	return d.CreateComponent(ComponentUser, user)
+}
+
+// DeleteUsercomponent deletes an user component for the deployment,
+// calling the respective methods con Vars and State objects
+func (d *DeploymentImpl) DeleteUsercomponent(user string) error {
+	return d.DeleteComponent(ComponentUser, user)
+}
+
+// RenameUsercomponent changes the name of an user component, moving its
+// variables, state and workdir directories
+func (d *DeploymentImpl) RenameUsercomponent(user string, newUser string) error {
+	// Checked before moving any directory, as it's the source of truth
+	err := d.Vars.Metadata.checkRenameUsercomponent(user, newUser)
+	if err != nil {
+		return err
+	}
+
+	err = d.State.RenameUsercomponent(user, newUser)
 	if err != nil {
 		return err
 	}
 
-	err = d.State.CreateUsercomponent(user)
+	err = d.Vars.RenameUsercomponent(user, newUser)
 	if err != nil {
+		// Rollback state directory move
+		d.State.RenameUsercomponent(newUser, user)
 		return err
 	}
 
+	// Workdir is generated again if it can't be moved
+	err = utils.DirectoryMove(d.fs, d.Workdir.mainUserPath(user), d.Workdir.mainUserPath(newUser))
+	if err != nil {
+		logrus.WithError(err).Debug("couldn't move user component workdir")
+		d.fs.RemoveAll(d.Workdir.mainUserPath(user))
+	}
+
 	return nil
 }
 
-// DeleteUsercomponent deletes an user component for the deployment,
-// calling the respective methods con Vars and State objects
-func (d *DeploymentImpl) DeleteUsercomponent(user string) error {
-	err := d.Vars.DeleteUsercomponent(user)
+// CopyUsercomponent creates a new user component with the plugins, flavour and
+// config variables of the source one, with empty state
+func (d *DeploymentImpl) CopyUsercomponent(src string, dst string) error {
+	err := d.Vars.CopyUsercomponent(src, dst)
 	if err != nil {
 		return err
 	}
 
-	err = d.State.DeleteUsercomponent(user)
+	err = d.State.CreateComponent(ComponentUser, dst)
 	if err != nil {
+		// Rollback variables and metadata copy
+		d.Vars.DeleteComponent(ComponentUser, dst)
 		return err
 	}
 
@@ -102,46 +180,224 @@ func (d *DeploymentImpl) DeleteUsercomponent(user string) error {
 
 // ListUsercomponents returns the user component names list
 func (d *DeploymentImpl) ListUsercomponents() ([]string, error) {
-	return d.Vars.Metadata.ListUsercomponents()
+	return d.ListComponents(ComponentUser)
+}
+
+// GetLabelsUser returns the labels of the specified user component
+func (d *DeploymentImpl) GetLabelsUser(user string) (map[string]string, error) {
+	return d.Vars.Metadata.GetUserLabels(user)
+}
+
+// SetLabelsUser adds or replaces labels of the specified user component, and deletes
+// the ones on the remove list
+func (d *DeploymentImpl) SetLabelsUser(user string, labels map[string]string, remove []string) error {
+	return d.Vars.Metadata.SetUserLabels(user, labels, remove)
+}
+
+// ListUsercomponentsWithSelector returns the sorted names of the user components
+// whose labels match the selector
+func (d *DeploymentImpl) ListUsercomponentsWithSelector(selector Selector) ([]string, error) {
+	return d.Vars.Metadata.ListUsercomponentsWithSelector(selector)
 }
 
 // CreatePluginGlobal adds a plugin to the global component, cloning its repo.
-func (d *DeploymentImpl) CreatePluginGlobal(name string, repo string, repoPath string) error {
+// Plugins it depends on must have been added before, unless withDependencies
+// is set, that adds them automatically. Plugins are kept ordered by their dependencies.
+func (d *DeploymentImpl) CreatePluginGlobal(name string, repo string, repoPath string, withDependencies bool) error {
+	added, err := d.createPluginGlobal(name, repo, repoPath, withDependencies)
+	if err != nil {
+		return err
+	}
+
+	err = d.sortPluginsGlobal()
+	if err != nil {
+		d.rollbackPluginsGlobal(added)
+		return err
+	}
+
+	return nil
+}
+
+// createPluginGlobal adds the plugin and its missing dependencies, returning the
+// names of all added plugins. On failure, added plugins are removed.
+func (d *DeploymentImpl) createPluginGlobal(name string, repo string, repoPath string, withDependencies bool) ([]string, error) {
 	// TODO: version and commit
 	pluginPath := d.getPluginPath(name)
 
 	err := d.fs.MkdirAll(pluginPath, 0755)
 	if err != nil {
-		return errors.Wrap(err, "couldn't create directory")
+		return nil, errors.Wrap(err, "couldn't create directory")
 	}
 
-	err = d.Vars.Metadata.CreateGlobalPlugin(name, repo, repoPath, "master", "")
+	err = d.Vars.Metadata.CreateGlobalPlugin(name, repo, repoPath, "", "")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	plugin := NewCTD(d.fs, pluginPath, name, repo, repoPath)
 
+	// The plugin follows the default branch of its repository
 	err = plugin.Clone()
+	if err == nil {
+		plugin.Ref, err = plugin.git.Branch()
+	}
+	if err == nil {
+		err = d.Vars.Metadata.SetGlobalPluginVersion(name, plugin.Ref)
+	}
 	if err != nil {
-		// Rollback metadata registration
-		d.Vars.Metadata.DeleteGlobalPlugin(name)
-		return err
+		// Rollback plugin clone and metadata registration
+		d.fs.RemoveAll(pluginPath)
+		d.Vars.Metadata.DeleteGlobalPlugin(name, true)
+		return nil, err
+	}
+
+	base, err := d.Base.Manifest()
+	if err == nil {
+		err = d.checkPluginCompatibility(plugin, base)
+	}
+	if err != nil {
+		// Rollback plugin clone and metadata registration
+		d.fs.RemoveAll(pluginPath)
+		d.Vars.Metadata.DeleteGlobalPlugin(name, true)
+		return nil, err
 	}
 
 	d.Plugins = append(d.Plugins, plugin)
-	return nil
+	added := []string{name}
+
+	manifest, err := plugin.Manifest()
+	if err != nil {
+		d.rollbackPluginsGlobal(added)
+		return nil, err
+	}
+
+	for _, dependency := range manifest.Dependencies {
+		if d.Vars.Metadata.globalPluginExists(dependency.Name) {
+			continue
+		}
+
+		if !withDependencies {
+			d.rollbackPluginsGlobal(added)
+			return nil, errors.Errorf("plugin %s depends on plugin %s, that must be added first", name, dependency.Name)
+		}
+		if dependency.Repo == "" {
+			d.rollbackPluginsGlobal(added)
+			return nil, errors.Errorf("plugin %s depends on plugin %s, but doesn't declare its repository", name, dependency.Name)
+		}
+
+		dependencyAdded, err := d.createPluginGlobal(dependency.Name, dependency.Repo, dependency.RepoPath, withDependencies)
+		if err != nil {
+			d.rollbackPluginsGlobal(added)
+			return nil, errors.Wrapf(err, "couldn't add plugin %s required by %s", dependency.Name, name)
+		}
+		added = append(added, dependencyAdded...)
+	}
+
+	return added, nil
 }
 
-// DeletePluginGlobal removes the plugin from the global component.
-// TODO: any usercomponent can't have the plugin installed, must be checked
-func (d *DeploymentImpl) DeletePluginGlobal(name string) error {
-	err := d.fs.RemoveAll(d.getPluginPath(name))
+// rollbackPluginsGlobal removes the plugins added on a failed operation
+func (d *DeploymentImpl) rollbackPluginsGlobal(names []string) {
+	for _, name := range names {
+		d.fs.RemoveAll(d.getPluginPath(name))
+		d.Vars.Metadata.DeleteGlobalPlugin(name, true)
+		d.removePlugin(name)
+	}
+}
+
+// DeletePluginGlobal removes the plugin from the global component. It can't
+// be removed while other plugins depend on it, or while user components use it
+// unless force is set, that removes it from them too. Plugin code is only
+// removed if metadata is updated successfully.
+func (d *DeploymentImpl) DeletePluginGlobal(name string, force bool) error {
+	err := d.CanDeletePluginGlobal(name, force)
+	if err != nil {
+		return err
+	}
+
+	if force {
+		err = d.checkPluginResources(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	pluginPath := d.getPluginPath(name)
+	trashPath := pluginPath + ".deleted"
+
+	exists, err := afero.DirExists(d.fs, pluginPath)
+	if err != nil {
+		return errors.Wrap(err, "couldn't determine if directory exists")
+	}
+	if exists {
+		err = d.fs.Rename(pluginPath, trashPath)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't move directory %s", pluginPath)
+		}
+	}
+
+	err = d.Vars.Metadata.DeleteGlobalPlugin(name, force)
+	if err != nil {
+		if exists {
+			restoreErr := d.fs.Rename(trashPath, pluginPath)
+			if restoreErr != nil {
+				logrus.WithError(restoreErr).WithField("path", pluginPath).Error("couldn't restore plugin directory")
+			}
+		}
+		return err
+	}
+
+	d.removePlugin(name)
+
+	err = d.fs.RemoveAll(trashPath)
 	if err != nil {
 		return errors.Wrap(err, "couldn't remove dir recursively")
 	}
 
-	return d.Vars.Metadata.DeleteGlobalPlugin(name)
+	return nil
+}
+
+// CanDeletePluginGlobal returns an error if the plugin can't be removed from the
+// global component with DeletePluginGlobal, without removing it. Plugin resources
+// aren't checked, so they can be destroyed after it.
+func (d *DeploymentImpl) CanDeletePluginGlobal(name string, force bool) error {
+	err := d.Vars.Metadata.CheckDeleteGlobalPlugin(name, force)
+	if err != nil {
+		return err
+	}
+
+	plugins, err := d.Vars.Metadata.ListGlobalPlugins()
+	if err != nil {
+		return err
+	}
+
+	return d.checkDependents(name, plugins)
+}
+
+// checkPluginResources returns an error if the plugin manages resources on any of the
+// component instances that use it, that would be destroyed on their next apply after
+// removing the plugin from them.
+func (d *DeploymentImpl) checkPluginResources(name string) error {
+	components := []string{}
+	for component := range d.Vars.Metadata.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range append([]string{ComponentUser}, components...) {
+		for _, instance := range d.Vars.Metadata.listComponentsWithPlugin(name, component) {
+			resources, err := d.ListPluginResourcesComponent(name, component, instance)
+			if err != nil {
+				return err
+			}
+			if len(resources) > 0 {
+				return errors.Errorf("plugin %s manages %d resources on %s, destroy them before removing it",
+					name, len(resources), describeComponent(component, instance))
+			}
+		}
+	}
+
+	return nil
 }
 
 // ListPluginsGlobal returns a list with the names of the plugins added
@@ -151,20 +407,34 @@ func (d *DeploymentImpl) ListPluginsGlobal() ([]string, error) {
 }
 
 // CreatePluginUser adds a plugin to the user component. Plugin must have been
-// added to de global component
-func (d *DeploymentImpl) CreatePluginUser(name string, user string) error {
-	return d.Vars.Metadata.CreateUserPlugin(name, user)
+// added to de global component. Plugins it depends on must have been added to the
+// user component before, unless withDependencies is set, that adds them automatically.
+func (d *DeploymentImpl) CreatePluginUser(name string, user string, withDependencies bool) error {
+	return d.CreatePluginComponent(name, ComponentUser, user, withDependencies)
 }
 
-// DeletePluginUser removes the plugin from the specified user component
+// DeletePluginUser removes the plugin from the specified user component. It can't
+// be removed while other plugins of the user component depend on it.
 func (d *DeploymentImpl) DeletePluginUser(name string, user string) error {
-	return d.Vars.Metadata.DeleteUserPlugin(name, user)
+	return d.DeletePluginComponent(name, ComponentUser, user)
 }
 
 // ListPluginsUser returns a list with the names of the plugins added to
 // the specified user component
 func (d *DeploymentImpl) ListPluginsUser(user string) ([]string, error) {
-	return d.Vars.Metadata.ListUserPlugins(user)
+	return d.ListPluginsComponent(ComponentUser, user)
+}
+
+// ListHooksGlobal returns the hooks declared for the event by the base and the
+// global component plugins, in execution order.
+func (d *DeploymentImpl) ListHooksGlobal(event string) ([]Hook, error) {
+	return d.ListHooksComponent(event, ComponentGlobal, "")
+}
+
+// ListHooksUser returns the hooks declared for the event by the base and the
+// plugins of the specified user component, in execution order.
+func (d *DeploymentImpl) ListHooksUser(event string, user string) ([]Hook, error) {
+	return d.ListHooksComponent(event, ComponentUser, user)
 }
 
 // GetFlavourGlobal returns the name of the configured flavour for the global component
@@ -179,55 +449,97 @@ func (d *DeploymentImpl) SetFlavourGlobal(flavour string) error {
 
 // GetFlavourUser returns the name of the configured flavour for the specified user component
 func (d *DeploymentImpl) GetFlavourUser(user string) (string, error) {
-	return d.Vars.Metadata.GetUserFlavour(user)
+	return d.GetFlavourComponent(ComponentUser, user)
 }
 
 // SetFlavourUser configures the flavour for the specified user component
 func (d *DeploymentImpl) SetFlavourUser(flavour string, user string) error {
-	return d.Vars.Metadata.SetUserFlavour(flavour, user)
+	return d.SetFlavourComponent(flavour, ComponentUser, user)
+}
+
+// ListFlavours returns the flavours declared by the base CTD manifest, the ones with
+// VTD files on the base and plugin CTDs and the ones configured on deployment
+// components, sorted and without duplicates
+func (d *DeploymentImpl) ListFlavours() ([]string, error) {
+	manifest, err := d.Base.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Vars.Metadata.load()
+	if err != nil {
+		return nil, err
+	}
+
+	flavours := map[string]bool{d.Vars.Metadata.Flavour: true}
+	for _, flavour := range manifest.Flavours {
+		flavours[flavour] = true
+	}
+	for _, ctd := range append([]*CTD{d.Base}, d.Plugins...) {
+		names, err := ctd.vtd.listFlavours()
+		if err != nil {
+			return nil, err
+		}
+		for _, flavour := range names {
+			flavours[flavour] = true
+		}
+	}
+	for _, component := range d.Vars.Metadata.UserComponents {
+		flavours[component.Flavour] = true
+	}
+	for _, instances := range d.Vars.Metadata.Components {
+		for _, component := range instances {
+			flavours[component.Flavour] = true
+		}
+	}
+	delete(flavours, "")
+
+	list := []string{}
+	for flavour := range flavours {
+		list = append(list, flavour)
+	}
+	sort.Strings(list)
+
+	return list, nil
 }
 
 // GenerateWorkdirGlobal combines deployment CTDs (main and plugins) to generate
 // the CTD to be applied by terraform. Returns main path where terraform must
 // be executed.
 func (d *DeploymentImpl) GenerateWorkdirGlobal() (string, error) {
-	err := d.Workdir.GenerateGlobal()
-	if err != nil {
-		return "", err
-	}
-	return d.Workdir.mainGlobalPath(), nil
+	return d.GenerateWorkdirComponent(ComponentGlobal, "")
 }
 
 // GenerateWorkdirUser combines deployment CTDs (main and plugins) to generate
 // the CTD to be applied by terraform
 func (d *DeploymentImpl) GenerateWorkdirUser(user string) (string, error) {
-	ok, err := d.Vars.Metadata.CheckUsercomponent(user)
-	if err != nil {
-		return "", err
-	}
-	if !ok {
-		return "", errors.Errorf("user component %s doesn't exist", user)
-	}
+	return d.GenerateWorkdirComponent(ComponentUser, user)
+}
 
-	err = d.Workdir.GenerateUser(user)
-	if err != nil {
-		return "", err
-	}
-	return d.Workdir.mainUserPath(user), nil
+// SaveLockFileGlobal copies the terraform dependency lock file from the global workdir
+// to the storage repository, to be committed with the variables.
+func (d *DeploymentImpl) SaveLockFileGlobal() error {
+	return d.SaveLockFileComponent(ComponentGlobal, "")
+}
+
+// SaveLockFileUser copies the terraform dependency lock file from the specified user
+// component workdir to the storage repository, to be committed with the variables.
+func (d *DeploymentImpl) SaveLockFileUser(user string) error {
+	return d.SaveLockFileComponent(ComponentUser, user)
 }
 
 // GenerateVariablesGlobal reads the variable files from the VTDs for the global component,
 // and copy them to the storage repository. Also returns a list with the filepaths and the order
 // that must be used when passing the variables to Terraform.
 func (d *DeploymentImpl) GenerateVariablesGlobal() ([]string, error) {
-	return d.Vars.GenerateGlobal()
+	return d.GenerateVariablesComponent(ComponentGlobal, "")
 }
 
 // GenerateVariablesUser reads the variable files from the VTDs for the specified user component,
 // and copy them to the storage repository. Also returns a list with the filepaths and the order
 // that must be used when passing the variables to Terraform.
 func (d *DeploymentImpl) GenerateVariablesUser(user string) ([]string, error) {
-	return d.Vars.GenerateUser(user)
+	return d.GenerateVariablesComponent(ComponentUser, user)
 }
 
 // GetVariableFilepath returns the path where is the variable file copied to the storage repository
@@ -235,7 +547,10 @@ func (d *DeploymentImpl) GenerateVariablesUser(user string) ([]string, error) {
 // Use empty string ("") on plugin parameter to obtain the base variable file and also on the
 // user parameter, to obtain the global component variable file.
 func (d *DeploymentImpl) GetVariableFilepath(kind string, plugin string, user string) (string, error) {
-	return d.Vars.GetVariableFilepath(kind, plugin, user)
+	if user == "" {
+		return d.GetVariableFilepathComponent(kind, plugin, ComponentGlobal, "")
+	}
+	return d.GetVariableFilepathComponent(kind, plugin, ComponentUser, user)
 }
 
 // ReadVariableFile returns the content of the variable file copied to the storage repository
@@ -243,17 +558,10 @@ func (d *DeploymentImpl) GetVariableFilepath(kind string, plugin string, user st
 // Use empty string ("") on plugin parameter to obtain the base variable file and also on the
 // user parameter, to obtain the global component variable file.
 func (d *DeploymentImpl) ReadVariableFile(kind string, plugin string, user string) (string, error) {
-	filepath, err := d.GetVariableFilepath(kind, plugin, user)
-	if err != nil {
-		return "", err
-	}
-
-	bytes, err := afero.ReadFile(d.fs, filepath)
-	if err != nil {
-		return "", errors.Wrap(err, "couldn't read file")
+	if user == "" {
+		return d.ReadVariableFileComponent(kind, plugin, ComponentGlobal, "")
 	}
-
-	return string(bytes), nil
+	return d.ReadVariableFileComponent(kind, plugin, ComponentUser, user)
 }
 
 // Push uploads vars and state to the respective repositories
@@ -287,11 +595,11 @@ func (d *DeploymentImpl) Pull() error {
 }
 
 func (d *DeploymentImpl) StateFilePathGlobal() string {
-	return d.State.FilePathGlobal()
+	return d.StateFilePathComponent(ComponentGlobal, "")
 }
 
 func (d *DeploymentImpl) StateFilePathUser(user string) string {
-	return d.State.FilePathUser(user)
+	return d.StateFilePathComponent(ComponentUser, user)
 }
 
 // TerraformVersion returns the terraform version that is being using with this
@@ -300,6 +608,12 @@ func (d *DeploymentImpl) TerraformVersion() string {
 	return d.Vars.Metadata.TerraformVersion
 }
 
+// Engine returns the name of the terraform compatible engine (terraform, opentofu)
+// that is being using with this specific deployment. Empty string means terraform.
+func (d *DeploymentImpl) Engine() string {
+	return d.Vars.Metadata.Engine
+}
+
 // CodeRepoURL returns the URL where is the terraform code that describes
 // infrastructure to be deployed in a sonatina way.
 func (d *DeploymentImpl) CodeRepoURL() string {
@@ -312,6 +626,50 @@ func (d *DeploymentImpl) CodeRepoPath() string {
 	return d.Vars.Metadata.RepoPath
 }
 
+// LogsPath returns the directory where the output of terraform runs is saved
+func (d *DeploymentImpl) LogsPath() string {
+	return filepath.Join(d.path, "logs")
+}
+
+// Upgrade updates base and plugins code to the last version of the branch they follow.
+// If the new versions aren't compatible, the previous ones are restored.
+func (d *DeploymentImpl) Upgrade() error {
+	ctds := append([]*CTD{d.Base}, d.Plugins...)
+
+	heads := []string{}
+	for _, ctd := range ctds {
+		head, err := ctd.Head()
+		if err != nil {
+			return err
+		}
+		heads = append(heads, head)
+	}
+
+	var err error
+	for _, ctd := range ctds {
+		err = ctd.Pull()
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = d.CheckCompatibility()
+	}
+
+	if err != nil {
+		logrus.WithError(err).Warn("upgrade failed, restoring previous code versions")
+		for i, ctd := range ctds {
+			resetErr := ctd.Reset(heads[i])
+			if resetErr != nil {
+				logrus.WithError(resetErr).WithField("path", ctd.path).Error("couldn't restore code version")
+			}
+		}
+		return errors.Wrap(err, "upgrade rejected")
+	}
+
+	return nil
+}
+
 // Purge removes all local files related to a deployment
 func (d *DeploymentImpl) Purge() error {
 	logrus.WithFields(logrus.Fields{
@@ -392,12 +750,12 @@ func Clone(name string, storageRepoURL string, fs afero.Fs, deploymentPath strin
 
 // Create creates and initializes a new Deployment object that has not been created before on any repository
 func Create(name string, storageRepoURL string, codeRepoURL string, codeRepoPath string,
-	terraformVersion string, flavour string, fs afero.Fs, deploymentPath string) error {
+	terraformVersion string, engine string, flavour string, fs afero.Fs, deploymentPath string) error {
 
 	deploy := newDeploymentImpl(name, fs, deploymentPath)
 
 	// TODO: paralelize
-	err := deploy.createVars(storageRepoURL, terraformVersion, codeRepoURL, codeRepoPath, flavour)
+	err := deploy.createVars(storageRepoURL, terraformVersion, engine, codeRepoURL, codeRepoPath, flavour)
 	if err != nil {
 		deploy.rollbackInitialize()
 		return err
@@ -409,19 +767,25 @@ func Create(name string, storageRepoURL string, codeRepoURL string, codeRepoPath
 		return err
 	}
 
-	err = deploy.Push("Initial commit")
+	err = deploy.newDeploymentCTDs()
 	if err != nil {
 		deploy.rollbackInitialize()
 		return err
 	}
 
-	err = deploy.newDeploymentCTDs()
+	err = deploy.cloneDeploymentCTDs()
 	if err != nil {
 		deploy.rollbackInitialize()
 		return err
 	}
 
-	err = deploy.cloneDeploymentCTDs()
+	err = deploy.CheckCompatibility()
+	if err != nil {
+		deploy.rollbackInitialize()
+		return err
+	}
+
+	err = deploy.Push("Initial commit")
 	if err != nil {
 		deploy.rollbackInitialize()
 		return err
@@ -463,6 +827,7 @@ func (d *DeploymentImpl) newDeploymentCTDs() error {
 	}
 
 	d.Base = NewCTD(d.fs, basePath, "", d.CodeRepoURL(), d.CodeRepoPath())
+	d.Base.Ref = d.Vars.Metadata.Version
 
 	for _, plugin := range d.Vars.Metadata.Plugins {
 		pluginPath := d.getPluginPath(plugin.Name)
@@ -472,7 +837,9 @@ func (d *DeploymentImpl) newDeploymentCTDs() error {
 			return errors.Wrap(err, "couldn't create directory")
 		}
 
-		d.Plugins = append(d.Plugins, NewCTD(d.fs, pluginPath, plugin.Name, plugin.Repo, plugin.RepoPath))
+		ctd := NewCTD(d.fs, pluginPath, plugin.Name, plugin.Repo, plugin.RepoPath)
+		ctd.Ref = plugin.Version
+		d.Plugins = append(d.Plugins, ctd)
 	}
 
 	return nil
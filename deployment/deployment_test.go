@@ -0,0 +1,144 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestRenameUsercomponentUnexisting(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	deploy := testNewUsercomponentsDeployment(t, fs, fs)
+
+	err := afero.WriteFile(fs, "/deployment/state/user/user3/terraform.tfstate", []byte("state"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = deploy.RenameUsercomponent("user3", "user4")
+	if err == nil {
+		t.Errorf("Expected error renaming an unexisting user component, obtained nil")
+	}
+
+	ok, err := afero.Exists(fs, "/deployment/state/user/user3/terraform.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("State of an unexisting user component must not be moved")
+	}
+}
+
+func TestCopyUsercomponentRollback(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	// state directory can't be created on a read only filesystem
+	deploy := testNewUsercomponentsDeployment(t, fs, afero.NewReadOnlyFs(fs))
+
+	err := deploy.CopyUsercomponent("user1", "user3")
+	if err == nil {
+		t.Errorf("Expected error copying an user component without state directory, obtained nil")
+	}
+
+	obtained, err := deploy.Vars.Metadata.ListUsercomponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"user1", "user2"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect user components.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+
+	ok, err := afero.Exists(fs, "/deployment/variables/user/user3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Variables directory of the copy must be removed")
+	}
+}
+
+func TestListFlavours(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/base/sonatina.yaml", []byte(`flavours:
+  - small
+  - default
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{
+		"/base/vtd/flavour/global/large.tfvars",
+		"/base/vtd/flavour/user/small.tfvars",
+		"/plugins/plugin1/vtd/flavour/user/xlarge.tfvars",
+		"/plugins/plugin1/vtd/flavour/user/README.md",
+	}
+	for _, file := range files {
+		err = afero.WriteFile(fs, file, []byte{}, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = testWriteMetadataReferenceFile(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := testNewMetadataEmpty(fs)
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		Base: NewCTD(fs, "/base", "", "example.com", "/"),
+		Plugins: []*CTD{
+			NewCTD(fs, "/plugins/plugin1", "plugin1", "example.com", "/"),
+		},
+		Vars: &Vars{Metadata: &metadata},
+	}
+
+	obtained, err := deploy.ListFlavours()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"default", "large", "small", "xlarge"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect flavours.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func testNewUsercomponentsDeployment(t *testing.T, fs afero.Fs, stateFs afero.Fs) *DeploymentImpl {
+	metadata := &Metadata{
+		fs:       fs,
+		filePath: "/deployment/variables/metadata.json",
+		UserComponents: map[string]userComponent{
+			"user1": {Flavour: "default"},
+			"user2": {Flavour: "default"},
+		},
+	}
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = afero.WriteFile(fs, "/deployment/variables/user/user1/user_config.tfvars", []byte(`a = "b"`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &DeploymentImpl{
+		fs:   fs,
+		path: "/deployment",
+		Vars: &Vars{
+			fs:       fs,
+			path:     "/deployment/variables",
+			Metadata: metadata,
+		},
+		State: &State{
+			fs:   stateFs,
+			path: "/deployment/state",
+		},
+	}
+}
@@ -0,0 +1,289 @@
+package deployment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arodriguezdlc/sonatina/gitw"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// Checks done by Diagnose, and by the deployment manager and terraform on `sonatina doctor`
+const (
+	CheckRegistry  string = "registry"
+	CheckDirectory string = "directory"
+	CheckVariables string = "variables"
+	CheckMetadata  string = "metadata"
+	CheckState     string = "state"
+	CheckCode      string = "code"
+	CheckWorkdir   string = "workdir"
+	CheckTerraform string = "terraform"
+)
+
+// Problem is an inconsistency found on the local storage of a deployment. Fixable
+// is set if it can be repaired safely, and Fixed if it has been repaired.
+type Problem struct {
+	Deployment string `json:"deployment"`
+	Check      string `json:"check"`
+	Message    string `json:"message"`
+	Fixable    bool   `json:"fixable"`
+	Fixed      bool   `json:"fixed"`
+}
+
+// doctor collects the problems found on a deployment, repairing them if fix is set
+type doctor struct {
+	deployment *DeploymentImpl
+	fix        bool
+
+	problems []Problem
+}
+
+// Diagnose checks the local storage of a deployment, that may be half created and
+// unusable by Get: the variables and state repositories and their branches, the
+// metadata file, the code of the base and plugin CTDs and the workdirs. If fix is
+// set, missing repositories are cloned, wrong branches checked out, and plugin and
+// workdir directories that don't belong to the deployment anymore are removed.
+func Diagnose(name string, storageRepoURL string, fs afero.Fs, deploymentPath string, fix bool) ([]Problem, error) {
+	d := &doctor{
+		deployment: newDeploymentImpl(name, fs, deploymentPath),
+		fix:        fix,
+		problems:   []Problem{},
+	}
+
+	ok, err := afero.DirExists(fs, deploymentPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't check directory %s", deploymentPath)
+	}
+	if !ok {
+		d.report(CheckDirectory, fmt.Sprintf("directory %s doesn't exist", deploymentPath), func() error {
+			return Clone(name, storageRepoURL, fs, deploymentPath)
+		})
+		return d.problems, nil
+	}
+
+	vars, err := d.deployment.newVars(storageRepoURL)
+	if err != nil {
+		return nil, err
+	}
+	if !d.diagnoseRepo(CheckVariables, vars.gitw, vars.path, storageRepoURL, varsBranch) {
+		return d.problems, nil
+	}
+
+	err = vars.Metadata.load()
+	if err != nil {
+		d.report(CheckMetadata, err.Error(), nil)
+		return d.problems, nil
+	}
+	d.deployment.Vars = vars
+
+	state, err := newState(fs, deploymentPath, storageRepoURL)
+	if err != nil {
+		return nil, err
+	}
+	d.diagnoseRepo(CheckState, state.gitw, state.path, storageRepoURL, stateBranch)
+	d.deployment.State = state
+
+	err = d.deployment.newDeploymentCTDs()
+	if err != nil {
+		return nil, err
+	}
+
+	baseOk := d.diagnoseCTD(d.deployment.Base)
+	for _, plugin := range d.deployment.Plugins {
+		d.diagnoseCTD(plugin)
+	}
+
+	err = d.diagnosePluginDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	if !baseOk {
+		return d.problems, nil
+	}
+
+	err = d.deployment.newWorkdir()
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.diagnoseWorkdir()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.problems, nil
+}
+
+// report adds a problem, that is repaired calling repair if it's not nil and fix is set
+func (d *doctor) report(check string, message string, repair func() error) bool {
+	problem := Problem{
+		Deployment: d.deployment.Name,
+		Check:      check,
+		Message:    message,
+		Fixable:    repair != nil,
+	}
+
+	if d.fix && repair != nil {
+		err := repair()
+		if err != nil {
+			problem.Message = fmt.Sprintf("%s: couldn't fix: %v", message, err)
+		} else {
+			problem.Fixed = true
+		}
+	}
+
+	d.problems = append(d.problems, problem)
+	return problem.Fixed
+}
+
+// diagnoseRepo checks that the storage repository branch is checked out on path.
+// Returns false if the repository isn't usable.
+func (d *doctor) diagnoseRepo(check string, git *gitw.Command, path string, repoURL string, branch string) bool {
+	current, err := git.Branch()
+	if err != nil {
+		empty, emptyErr := d.isMissingOrEmpty(path)
+		if emptyErr != nil {
+			d.report(check, emptyErr.Error(), nil)
+			return false
+		}
+		if !empty {
+			d.report(check, err.Error(), nil)
+			return false
+		}
+		return d.report(check, fmt.Sprintf("repository on %s isn't cloned", path), func() error {
+			return git.CloneBranch(repoURL, branch)
+		})
+	}
+
+	if current != branch {
+		d.report(check, fmt.Sprintf("branch %s is checked out instead of %s", current, branch), func() error {
+			return git.CheckoutBranch(branch)
+		})
+	}
+
+	return true
+}
+
+// diagnoseCTD checks that the CTD repository is cloned. Returns false if it isn't usable.
+func (d *doctor) diagnoseCTD(ctd *CTD) bool {
+	_, err := ctd.Head()
+	if err == nil {
+		return true
+	}
+
+	empty, emptyErr := d.isMissingOrEmpty(ctd.path)
+	if emptyErr != nil {
+		d.report(CheckCode, emptyErr.Error(), nil)
+		return false
+	}
+	if !empty {
+		d.report(CheckCode, fmt.Sprintf("%s code: %v", ctd.description(), err), nil)
+		return false
+	}
+
+	return d.report(CheckCode, fmt.Sprintf("%s code isn't cloned", ctd.description()), ctd.Clone)
+}
+
+// isMissingOrEmpty returns true if the directory doesn't exist or is empty, so
+// a repository can be cloned on it
+func (d *doctor) isMissingOrEmpty(path string) (bool, error) {
+	exists, err := afero.Exists(d.deployment.fs, path)
+	if err != nil {
+		return false, errors.Wrapf(err, "couldn't check directory %s", path)
+	}
+	if !exists {
+		return true, nil
+	}
+
+	empty, err := afero.IsEmpty(d.deployment.fs, path)
+	if err != nil {
+		return false, errors.Wrapf(err, "couldn't check directory %s", path)
+	}
+
+	return empty, nil
+}
+
+// diagnosePluginDirs reports the plugin directories of plugins removed from metadata
+func (d *doctor) diagnosePluginDirs() error {
+	pluginsPath := filepath.Join(d.deployment.path, "code", "plugins")
+	entries, err := afero.ReadDir(d.deployment.fs, pluginsPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "couldn't read directory %s", pluginsPath)
+	}
+
+	for _, entry := range entries {
+		if d.deployment.Vars.Metadata.globalPluginExists(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(pluginsPath, entry.Name())
+		d.report(CheckCode, fmt.Sprintf("plugin %s isn't on metadata, but directory %s exists", entry.Name(), path), func() error {
+			return d.deployment.fs.RemoveAll(path)
+		})
+	}
+
+	return nil
+}
+
+// diagnoseWorkdir reports the workdirs of component instances that don't exist,
+// or whose component type isn't declared anymore
+func (d *doctor) diagnoseWorkdir() error {
+	types, err := d.deployment.ListComponentTypes()
+	if err != nil {
+		d.report(CheckCode, err.Error(), nil)
+		return nil
+	}
+
+	mainPath := filepath.Join(d.deployment.Workdir.path, "main")
+	entries, err := afero.ReadDir(d.deployment.fs, mainPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "couldn't read directory %s", mainPath)
+	}
+
+	declared := map[string]bool{}
+	for _, t := range types {
+		declared[t] = true
+	}
+
+	for _, entry := range entries {
+		component := entry.Name()
+		if component == ComponentGlobal {
+			continue
+		}
+
+		path := filepath.Join(mainPath, component)
+		if !entry.IsDir() || !declared[component] {
+			d.reportOrphanWorkdir(path, fmt.Sprintf("component type %s isn't declared", component))
+			continue
+		}
+
+		instances, err := afero.ReadDir(d.deployment.fs, path)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't read directory %s", path)
+		}
+
+		for _, instance := range instances {
+			if _, ok := d.deployment.Vars.Metadata.instances(component)[instance.Name()]; ok {
+				continue
+			}
+			d.reportOrphanWorkdir(filepath.Join(path, instance.Name()), describeComponent(component, instance.Name())+" doesn't exist")
+		}
+	}
+
+	return nil
+}
+
+func (d *doctor) reportOrphanWorkdir(path string, reason string) {
+	d.report(CheckWorkdir, fmt.Sprintf("workdir %s exists, but %s", path, reason), func() error {
+		return d.deployment.fs.RemoveAll(path)
+	})
+}
@@ -0,0 +1,133 @@
+package deployment
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arodriguezdlc/sonatina/gitw"
+	"github.com/spf13/afero"
+)
+
+func TestDiagnoseMissingDirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	obtained, err := Diagnose("deployment", "git@test.com/storage", fs, "deployment", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Problem{
+		{
+			Deployment: "deployment",
+			Check:      CheckDirectory,
+			Message:    "directory deployment doesn't exist",
+			Fixable:    true,
+		},
+	}
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect problems.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestDiagnoseOrphanDirectories(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	workdir, err := testNewWorkdir(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy := workdir.deployment
+	deploy.Vars.Metadata.Plugins = []globalPlugin{{Name: "plugin1"}, {Name: "plugin2"}}
+
+	paths := []string{
+		filepath.Join("deployment", "code", "plugins", "plugin1"),
+		filepath.Join("deployment", "code", "plugins", "plugin3"),
+		filepath.Join("deployment", "workdir", "main", "global"),
+		filepath.Join("deployment", "workdir", "main", "user", "user1"),
+		filepath.Join("deployment", "workdir", "main", "user", "user3"),
+		filepath.Join("deployment", "workdir", "main", "undeclared", "instance1"),
+	}
+	for _, path := range paths {
+		err = fs.MkdirAll(path, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := &doctor{deployment: deploy, fix: true, problems: []Problem{}}
+
+	err = d.diagnosePluginDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.diagnoseWorkdir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedChecks := []string{CheckCode, CheckWorkdir, CheckWorkdir}
+	obtainedChecks := []string{}
+	for _, problem := range d.problems {
+		if !problem.Fixed {
+			t.Errorf("Problem not fixed: %s", problem.Message)
+		}
+		obtainedChecks = append(obtainedChecks, problem.Check)
+	}
+	if !reflect.DeepEqual(expectedChecks, obtainedChecks) {
+		t.Errorf("Incorrect problem checks.\n\n Expected: %v\n\n Obtained: %v\n", expectedChecks, obtainedChecks)
+	}
+
+	for i, path := range paths {
+		expected := i != 1 && i != 4 && i != 5
+		obtained, err := afero.DirExists(fs, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != obtained {
+			t.Errorf("Incorrect existence of %s.\n\n Expected: %v\n\n Obtained: %v\n", path, expected, obtained)
+		}
+	}
+}
+
+func TestDiagnoseMissingRepositories(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	deploy := newDeploymentImpl("deployment", fs, "deployment")
+	d := &doctor{deployment: deploy, fix: false, problems: []Problem{}}
+
+	path := filepath.Join("deployment", "vars")
+	git, err := gitw.NewCommand(fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := d.diagnoseRepo(CheckVariables, git, path, "git@test.com/storage", varsBranch)
+	if ok {
+		t.Errorf("Missing repository must not be usable")
+	}
+
+	ok = d.diagnoseCTD(NewCTD(fs, filepath.Join("deployment", "code", "plugins", "plugin1"), "plugin1", "", ""))
+	if ok {
+		t.Errorf("Missing CTD must not be usable")
+	}
+
+	expected := []Problem{
+		{
+			Deployment: "deployment",
+			Check:      CheckVariables,
+			Message:    "repository on " + path + " isn't cloned",
+			Fixable:    true,
+		},
+		{
+			Deployment: "deployment",
+			Check:      CheckCode,
+			Message:    "plugin plugin1 code isn't cloned",
+			Fixable:    true,
+		},
+	}
+	if !reflect.DeepEqual(expected, d.problems) {
+		t.Errorf("Incorrect problems.\n\n Expected: %v\n\n Obtained: %v\n", expected, d.problems)
+	}
+}
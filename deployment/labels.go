@@ -0,0 +1,131 @@
+package deployment
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var labelKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+var labelValueRegexp = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)?$`)
+
+// Selector filters user components by their labels. All its requirements
+// must be met by the labels.
+type Selector []labelRequirement
+
+type labelRequirement struct {
+	key      string
+	operator string
+	value    string
+}
+
+// ParseSelector parses a comma separated list of label requirements, that can be
+// `key=value`, `key!=value`, `key` (label exists) or `!key` (label doesn't exist).
+// An empty string matches everything.
+func ParseSelector(selector string) (Selector, error) {
+	requirements := Selector{}
+	if strings.TrimSpace(selector) == "" {
+		return requirements, nil
+	}
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var requirement labelRequirement
+
+		switch {
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			requirement = labelRequirement{key: parts[0], operator: "!=", value: parts[1]}
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			requirement = labelRequirement{key: parts[0], operator: "=", value: strings.TrimPrefix(parts[1], "=")}
+		case strings.HasPrefix(term, "!"):
+			requirement = labelRequirement{key: strings.TrimPrefix(term, "!"), operator: "!"}
+		default:
+			requirement = labelRequirement{key: term, operator: "exists"}
+		}
+
+		requirement.key = strings.TrimSpace(requirement.key)
+		requirement.value = strings.TrimSpace(requirement.value)
+		err := ValidateLabel(requirement.key, requirement.value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid selector %s", selector)
+		}
+
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// Matches returns true if the labels meet all the selector requirements
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		value, ok := labels[requirement.key]
+
+		switch requirement.operator {
+		case "=":
+			if !ok || value != requirement.value {
+				return false
+			}
+		case "!=":
+			if ok && value == requirement.value {
+				return false
+			}
+		case "!":
+			if ok {
+				return false
+			}
+		default:
+			if !ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// ParseLabels parses a list of `key=value` labels to set and `key-` labels to remove
+func ParseLabels(args []string) (map[string]string, []string, error) {
+	labels := map[string]string{}
+	remove := []string{}
+
+	for _, arg := range args {
+		if strings.HasSuffix(arg, "-") && !strings.Contains(arg, "=") {
+			key := strings.TrimSuffix(arg, "-")
+			err := ValidateLabel(key, "")
+			if err != nil {
+				return nil, nil, err
+			}
+			remove = append(remove, key)
+			continue
+		}
+
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, errors.Errorf("invalid label %s, must be key=value or key- to remove it", arg)
+		}
+
+		err := ValidateLabel(parts[0], parts[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		labels[parts[0]] = parts[1]
+	}
+
+	return labels, remove, nil
+}
+
+// ValidateLabel checks a label key and value are valid. Keys are made of alphanumeric
+// characters, '.', '_', '-' and '/', and values can't contain '/'.
+func ValidateLabel(key string, value string) error {
+	if !labelKeyRegexp.MatchString(key) {
+		return errors.Errorf("invalid label key %q", key)
+	}
+	if !labelValueRegexp.MatchString(value) {
+		return errors.Errorf("invalid label value %q for key %s", value, key)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"team": "data", "region": "eu"}
+
+	tests := []struct {
+		selector string
+		expected bool
+	}{
+		{"", true},
+		{"team=data", true},
+		{"team==data", true},
+		{"team=data,region=us", false},
+		{"tier!=prod", true},
+		{"team!=data", false},
+		{"region", true},
+		{"tier", false},
+		{"!tier", true},
+		{"!team", false},
+	}
+
+	for _, test := range tests {
+		selector, err := ParseSelector(test.selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		obtained := selector.Matches(labels)
+		if obtained != test.expected {
+			t.Errorf("Incorrect match of selector %q, expected: %v, obtained: %v", test.selector, test.expected, obtained)
+		}
+	}
+}
+
+func TestParseSelectorInvalid(t *testing.T) {
+	for _, selector := range []string{"=data", "team=da ta", "team,,tier"} {
+		_, err := ParseSelector(selector)
+		if err == nil {
+			t.Errorf("Expected error for invalid selector %q, obtained nil", selector)
+		}
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, remove, err := ParseLabels([]string{"team=data", "tier=", "region-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedLabels := map[string]string{"team": "data", "tier": ""}
+	if !reflect.DeepEqual(expectedLabels, labels) {
+		t.Errorf("Incorrect labels.\n\n Expected: %v\n\n Obtained: %v\n", expectedLabels, labels)
+	}
+
+	expectedRemove := []string{"region"}
+	if !reflect.DeepEqual(expectedRemove, remove) {
+		t.Errorf("Incorrect removed labels.\n\n Expected: %v\n\n Obtained: %v\n", expectedRemove, remove)
+	}
+
+	_, _, err = ParseLabels([]string{"team"})
+	if err == nil {
+		t.Errorf("Label without value must return an error")
+	}
+}
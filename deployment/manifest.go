@@ -0,0 +1,168 @@
+package deployment
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/arodriguezdlc/sonatina/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+const manifestFileName string = "sonatina.yaml"
+
+// Hook events supported on CTD manifests
+const (
+	HookPreInit     string = "pre-init"
+	HookPreApply    string = "pre-apply"
+	HookPostApply   string = "post-apply"
+	HookPreDestroy  string = "pre-destroy"
+	HookPostDestroy string = "post-destroy"
+)
+
+var hookEvents = []string{HookPreInit, HookPreApply, HookPostApply, HookPreDestroy, HookPostDestroy}
+
+// Manifest describes a CTD. It's read from the optional sonatina.yaml file
+// on the CTD root directory. Terraform and Base are version constraints, the
+// latter declaring the base versions a plugin is compatible with. Empty fields
+// aren't checked. Components are only read from the base CTD. Sensitive outputs
+// are only exposed to the hooks of CTDs that set HookSensitiveOutputs.
+type Manifest struct {
+	Name         string              `yaml:"name"`
+	Version      string              `yaml:"version"`
+	Terraform    string              `yaml:"terraform"`
+	Base         string              `yaml:"base"`
+	Flavours     []string            `yaml:"flavours"`
+	Dependencies []Dependency        `yaml:"dependencies"`
+	Overrides    []string            `yaml:"overrides"`
+	Hooks        map[string][]string `yaml:"hooks"`
+	Components   []ComponentType     `yaml:"components"`
+
+	HookSensitiveOutputs bool `yaml:"hook_sensitive_outputs"`
+}
+
+// Dependency declares a plugin required by a plugin. Repo and RepoPath are
+// used to add it automatically when it's missing.
+type Dependency struct {
+	Name     string `yaml:"name"`
+	Repo     string `yaml:"repo"`
+	RepoPath string `yaml:"repo_path"`
+}
+
+// Hook is a command declared by a CTD to be executed around terraform operations.
+// It's executed from the CTD directory. SensitiveOutputs is set if the CTD allows
+// its hooks to receive sensitive outputs.
+type Hook struct {
+	CTD              string
+	Path             string
+	Command          string
+	SensitiveOutputs bool
+}
+
+// readManifest reads and validates the manifest file from the specified CTD path.
+// If the CTD doesn't have a manifest, an empty one is returned.
+func readManifest(fs afero.Fs, path string) (*Manifest, error) {
+	manifest := &Manifest{
+		Hooks: map[string][]string{},
+	}
+
+	file := filepath.Join(path, manifestFileName)
+	data, err := afero.ReadFile(fs, file)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read manifest %s", file)
+	}
+
+	err = yaml.UnmarshalStrict(data, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse manifest %s", file)
+	}
+
+	err = manifest.validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid manifest %s", file)
+	}
+
+	return manifest, nil
+}
+
+func (m *Manifest) validate() error {
+	if m.Version != "" {
+		err := utils.ValidateVersion(m.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, constraint := range []string{m.Terraform, m.Base} {
+		if constraint != "" {
+			err := utils.ValidateVersionConstraint(constraint)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, dependency := range m.Dependencies {
+		if dependency.Name == "" {
+			return errors.New("dependencies must declare the plugin name")
+		}
+	}
+
+	names := map[string]bool{}
+	for _, component := range m.Components {
+		err := component.validate()
+		if err != nil {
+			return err
+		}
+		if names[component.Name] {
+			return errors.Errorf("component type %s declared twice", component.Name)
+		}
+		names[component.Name] = true
+	}
+
+	for event := range m.Hooks {
+		if !isHookEvent(event) {
+			return errors.Errorf("unknown hook event %s, must be one of %v", event, hookEvents)
+		}
+	}
+	return nil
+}
+
+// overrides returns true if the CTD declares it replaces the file or module with
+// the specified path, relative to the CTD root directory.
+func (m *Manifest) overrides(path string) bool {
+	for _, override := range m.Overrides {
+		if filepath.ToSlash(filepath.Clean(override)) == filepath.ToSlash(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// supportsFlavour returns true if the CTD declares the flavour, or doesn't
+// declare any flavour
+func (m *Manifest) supportsFlavour(flavour string) bool {
+	if len(m.Flavours) == 0 || flavour == "" {
+		return true
+	}
+
+	for _, f := range m.Flavours {
+		if f == flavour {
+			return true
+		}
+	}
+	return false
+}
+
+func isHookEvent(event string) bool {
+	for _, hookEvent := range hookEvents {
+		if event == hookEvent {
+			return true
+		}
+	}
+	return false
+}
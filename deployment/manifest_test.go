@@ -0,0 +1,145 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestListHooks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/ctd/sonatina.yaml", []byte(`hooks:
+  pre-apply:
+    - ./scripts/build-lambda.sh
+  post-apply:
+    - ./scripts/smoke-test.sh
+    - echo done
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctd := NewCTD(fs, "/ctd", "example", "example.com", "/")
+
+	obtainedHooks, err := ctd.ListHooks(HookPostApply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHooks := []Hook{
+		{CTD: "plugin example", Path: "/ctd", Command: "./scripts/smoke-test.sh"},
+		{CTD: "plugin example", Path: "/ctd", Command: "echo done"},
+	}
+
+	if !reflect.DeepEqual(expectedHooks, obtainedHooks) {
+		t.Errorf("Incorrect hooks.\n\n Expected: %v\n\n Obtained: %v\n", expectedHooks, obtainedHooks)
+	}
+}
+
+func TestListHooksWithSensitiveOutputs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/ctd/sonatina.yaml", []byte(`hook_sensitive_outputs: true
+hooks:
+  post-apply:
+    - ./scripts/register.sh
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctd := NewCTD(fs, "/ctd", "example", "example.com", "/")
+
+	obtainedHooks, err := ctd.ListHooks(HookPostApply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHooks := []Hook{
+		{CTD: "plugin example", Path: "/ctd", Command: "./scripts/register.sh", SensitiveOutputs: true},
+	}
+
+	if !reflect.DeepEqual(expectedHooks, obtainedHooks) {
+		t.Errorf("Incorrect hooks.\n\n Expected: %v\n\n Obtained: %v\n", expectedHooks, obtainedHooks)
+	}
+}
+
+func TestListHooksWithoutManifest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctd := NewCTD(fs, "/ctd", "", "example.com", "/")
+
+	obtainedHooks, err := ctd.ListHooks(HookPreInit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(obtainedHooks) != 0 {
+		t.Errorf("Incorrect hooks.\n\n Expected: %v\n\n Obtained: %v\n", []Hook{}, obtainedHooks)
+	}
+}
+
+func TestReadManifestWithUnknownHook(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/ctd/sonatina.yaml", []byte(`hooks:
+  post-init:
+    - echo init
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = readManifest(fs, "/ctd")
+	if err == nil {
+		t.Errorf("Manifest with unknown hook event must return an error")
+	}
+}
+
+func TestListComponentTypes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/base/sonatina.yaml", []byte(`components:
+  - name: database
+    description: Managed database instances
+  - name: cache
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		Base: NewCTD(fs, "/base", "", "example.com", "/"),
+	}
+
+	obtainedTypes, err := deploy.ListComponentTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTypes := []string{"global", "user", "database", "cache"}
+
+	if !reflect.DeepEqual(expectedTypes, obtainedTypes) {
+		t.Errorf("Incorrect component types.\n\n Expected: %v\n\n Obtained: %v\n", expectedTypes, obtainedTypes)
+	}
+
+	err = deploy.checkComponentType("queue")
+	if err == nil {
+		t.Errorf("Undeclared component type must return an error")
+	}
+}
+
+func TestReadManifestWithReservedComponent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/ctd/sonatina.yaml", []byte(`components:
+  - name: user
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = readManifest(fs, "/ctd")
+	if err == nil {
+		t.Errorf("Manifest declaring a reserved component type must return an error")
+	}
+}
@@ -3,8 +3,8 @@ package deployment
 import (
 	"encoding/json"
 	"path/filepath"
+	"sort"
 
-	"github.com/arodriguezdlc/sonatina/utils"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
@@ -18,6 +18,7 @@ type Metadata struct {
 	filePath string
 
 	TerraformVersion string                   `json:"terraform_version"`
+	Engine           string                   `json:"engine,omitempty"`
 	Repo             string                   `json:"repo"`
 	RepoPath         string                   `json:"repo_path"`
 	Version          string                   `json:"version"`
@@ -25,11 +26,18 @@ type Metadata struct {
 	Flavour          string                   `json:"flavour"`
 	UserComponents   map[string]userComponent `json:"user_components"`
 	Plugins          []globalPlugin           `json:"plugins"`
+
+	// Components holds the instances of the component types declared by the base
+	// CTD, by type and instance name. User components are kept on UserComponents.
+	Components map[string]map[string]userComponent `json:"components,omitempty"`
 }
 
+// userComponent is an instance of a component type other than global,
+// usually an user component
 type userComponent struct {
-	Plugins []userPlugin `json:"plugins"`
-	Flavour string       `json:"flavour"`
+	Plugins []userPlugin      `json:"plugins"`
+	Flavour string            `json:"flavour"`
+	Labels  map[string]string `json:"labels,omitempty"`
 }
 
 type globalPlugin struct {
@@ -74,23 +82,42 @@ func (m *Metadata) CreateGlobalPlugin(name string, repo string, repoPath string,
 	return nil
 }
 
-// DeleteGlobalPlugin deletes the specified plugin from the
-// global component
+// DeleteGlobalPlugin deletes the specified plugin from the global component.
+// If any user component or instance of other component type uses the plugin, an
+// error is returned unless force is set, that removes the plugin from them too.
 // XXX: this method isn't thread safe
-func (m *Metadata) DeleteGlobalPlugin(name string) error {
+func (m *Metadata) DeleteGlobalPlugin(name string, force bool) error {
 	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	// TODO: check if an user component has the plugin assigned. It can't be deleted
-	// until all user components have been deleted its plugin
+	err = m.checkDeleteGlobalPlugin(name, force)
+	if err != nil {
+		return err
+	}
 
 	index, err := m.getGlobalPluginIndex(name)
 	if err != nil {
 		return err
 	}
 
+	components := []string{}
+	for component := range m.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range append([]string{ComponentUser}, components...) {
+		for _, instance := range m.listComponentsWithPlugin(name, component) {
+			i, err := m.getComponentPluginIndex(name, component, instance)
+			if err != nil {
+				return err
+			}
+			m.deleteComponentPluginWithIndex(i, component, instance)
+		}
+	}
+
 	m.deleteGlobalPluginWithIndex(index)
 
 	err = m.save()
@@ -101,6 +128,39 @@ func (m *Metadata) DeleteGlobalPlugin(name string) error {
 	return nil
 }
 
+// SetGlobalPluginVersion saves the ref followed by a global plugin
+// XXX: this method isn't thread safe
+func (m *Metadata) SetGlobalPluginVersion(name string, version string) error {
+	err := m.load()
+	if err != nil {
+		return err
+	}
+
+	i, err := m.getGlobalPluginIndex(name)
+	if err != nil {
+		return err
+	}
+	m.Plugins[i].Version = version
+
+	err = m.save()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckDeleteGlobalPlugin returns an error if the specified plugin can't be deleted
+// from the global component with DeleteGlobalPlugin, without deleting it
+func (m *Metadata) CheckDeleteGlobalPlugin(name string, force bool) error {
+	err := m.load()
+	if err != nil {
+		return err
+	}
+
+	return m.checkDeleteGlobalPlugin(name, force)
+}
+
 // ListGlobalPlugins loads metadata and list plugins added to
 // the global component
 func (m *Metadata) ListGlobalPlugins() ([]string, error) {
@@ -112,36 +172,88 @@ func (m *Metadata) ListGlobalPlugins() ([]string, error) {
 	return m.listGlobalPlugins()
 }
 
+// SortGlobalPlugins reorders the global component plugins as specified by the
+// plugin names list, that must contain all of them.
+// XXX: this method isn't thread safe
+func (m *Metadata) SortGlobalPlugins(order []string) error {
+	err := m.load()
+	if err != nil {
+		return err
+	}
+
+	if len(order) != len(m.Plugins) {
+		return errors.Errorf("plugin order %v doesn't match global plugins", order)
+	}
+
+	plugins := []globalPlugin{}
+	for _, name := range order {
+		plugin, err := m.getGlobalPlugin(name)
+		if err != nil {
+			return err
+		}
+		plugins = append(plugins, plugin)
+	}
+	m.Plugins = plugins
+
+	for user := range m.UserComponents {
+		m.sortComponentPlugins(ComponentUser, user)
+	}
+	for component, instances := range m.Components {
+		for instance := range instances {
+			m.sortComponentPlugins(component, instance)
+		}
+	}
+
+	return m.save()
+}
+
 // CreateUserPlugin adds the specified plugin to the specified user component
 // XXX: this method isn't thread safe
 func (m *Metadata) CreateUserPlugin(name string, user string) error {
+	return m.CreateComponentPlugin(name, ComponentUser, user)
+}
+
+// DeleteUserPlugin deletes the specified plugin from the specified
+// user component
+// XXX: this method isn't thread safe
+func (m *Metadata) DeleteUserPlugin(name string, user string) error {
+	return m.DeleteComponentPlugin(name, ComponentUser, user)
+}
+
+// ListUserPlugins loads metadata and list plugins added to a
+// specified user
+func (m *Metadata) ListUserPlugins(user string) ([]string, error) {
+	return m.ListComponentPlugins(ComponentUser, user)
+}
+
+// CreateComponentPlugin adds the specified plugin to an instance of a component type
+// XXX: this method isn't thread safe
+func (m *Metadata) CreateComponentPlugin(name string, component string, instance string) error {
 	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	ok, err := m.checkUsercomponent(user)
+	err = m.checkComponentExists(component, instance)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return errors.Errorf("user component %s doesn't exist", user)
-	}
 
 	if !m.globalPluginExists(name) {
 		return errors.Errorf("global plugin %s doesn't exist", name)
 	}
 
-	if m.userPluginExists(name, user) {
-		return errors.Errorf("user plugin %s already exists for user %s", name, user)
+	if m.componentPluginExists(name, component, instance) {
+		return errors.Errorf("plugin %s already exists for %s", name, describeComponent(component, instance))
 	}
 
 	plugin := userPlugin{
 		Name: name,
 	}
-	userComponent := m.UserComponents[user]
-	userComponent.Plugins = append(userComponent.Plugins, plugin)
-	m.UserComponents[user] = userComponent
+	c := m.instances(component)[instance]
+	c.Plugins = append(c.Plugins, plugin)
+	m.setInstance(component, instance, c)
+	m.sortComponentPlugins(component, instance)
 
 	err = m.save()
 	if err != nil {
@@ -151,78 +263,80 @@ func (m *Metadata) CreateUserPlugin(name string, user string) error {
 	return nil
 }
 
-// DeleteUserPlugin deletes the specified plugin from the specified
-// user component
+// DeleteComponentPlugin deletes the specified plugin from an instance of a component type
 // XXX: this method isn't thread safe
-func (m *Metadata) DeleteUserPlugin(name string, user string) error {
+func (m *Metadata) DeleteComponentPlugin(name string, component string, instance string) error {
 	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	ok, err := m.checkUsercomponent(user)
+	err = m.checkDeleteComponentPlugin(name, component, instance)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return errors.Errorf("user component %s doesn't exist", user)
-	}
 
-	if !m.globalPluginExists(name) {
-		return errors.Errorf("global plugin %s doesn't exist", name)
+	index, err := m.getComponentPluginIndex(name, component, instance)
+	if err != nil {
+		return err
 	}
 
-	if !m.userPluginExists(name, user) {
-		return errors.Errorf("user plugin %s doesn't exist for user %s", name, user)
-	}
+	m.deleteComponentPluginWithIndex(index, component, instance)
 
-	index, err := m.getUserPluginIndex(name, user)
+	err = m.save()
 	if err != nil {
 		return err
 	}
 
-	m.deleteUserPluginWithIndex(index, user)
+	return nil
+}
 
-	err = m.save()
+// CheckDeleteComponentPlugin returns an error if the specified plugin can't be deleted
+// from an instance of a component type with DeleteComponentPlugin, without deleting it
+func (m *Metadata) CheckDeleteComponentPlugin(name string, component string, instance string) error {
+	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return m.checkDeleteComponentPlugin(name, component, instance)
 }
 
-// ListUserPlugins loads metadata and list plugins added to a
-// specified user
-func (m *Metadata) ListUserPlugins(user string) ([]string, error) {
+// ListComponentPlugins loads metadata and list plugins added to an instance
+// of a component type
+func (m *Metadata) ListComponentPlugins(component string, instance string) ([]string, error) {
 	err := m.load()
 	if err != nil {
 		return []string{}, err
 	}
 
-	ok, err := m.checkUsercomponent(user)
+	err = m.checkComponentExists(component, instance)
 	if err != nil {
 		return []string{}, err
 	}
-	if !ok {
-		return []string{}, errors.Errorf("user component %s doesn't exist", user)
-	}
 
-	return m.listUserPlugins(user)
+	return m.listComponentPlugins(component, instance)
 }
 
 // CreateUsercomponent updates metadata with a new user component
 // XXX: this method isn't thread safe
 func (m *Metadata) CreateUsercomponent(user string) error {
+	return m.CreateComponent(ComponentUser, user)
+}
+
+// CreateComponent updates metadata with a new instance of a component type
+// XXX: this method isn't thread safe
+func (m *Metadata) CreateComponent(component string, instance string) error {
 	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	_, ok := m.UserComponents[user]
+	_, ok := m.instances(component)[instance]
 	if ok {
-		return errors.Errorf("user component %s already exists", user)
+		return errors.Errorf("%s already exists", describeComponent(component, instance))
 	}
-	m.UserComponents[user] = m.newUsercomponent()
+	m.setInstance(component, instance, m.newUsercomponent())
 
 	err = m.save()
 	if err != nil {
@@ -232,19 +346,88 @@ func (m *Metadata) CreateUsercomponent(user string) error {
 	return nil
 }
 
+// RenameUsercomponent updates metadata changing the name of an user component
+// XXX: this method isn't thread safe
+func (m *Metadata) RenameUsercomponent(user string, newUser string) error {
+	err := m.checkRenameUsercomponent(user, newUser)
+	if err != nil {
+		return err
+	}
+
+	m.UserComponents[newUser] = m.UserComponents[user]
+	delete(m.UserComponents, user)
+
+	return m.save()
+}
+
+// checkRenameUsercomponent loads metadata, returning an error if the user component
+// doesn't exist or the new name is already used
+func (m *Metadata) checkRenameUsercomponent(user string, newUser string) error {
+	err := m.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := m.UserComponents[user]; !ok {
+		return errors.Errorf("user component %s doesn't exist", user)
+	}
+	if _, ok := m.UserComponents[newUser]; ok {
+		return errors.Errorf("user component %s already exists", newUser)
+	}
+
+	return nil
+}
+
+// CopyUsercomponent updates metadata adding a new user component with the same
+// plugins, flavour and labels than the source one
+// XXX: this method isn't thread safe
+func (m *Metadata) CopyUsercomponent(src string, dst string) error {
+	err := m.load()
+	if err != nil {
+		return err
+	}
+
+	component, ok := m.UserComponents[src]
+	if !ok {
+		return errors.Errorf("user component %s doesn't exist", src)
+	}
+	if _, ok := m.UserComponents[dst]; ok {
+		return errors.Errorf("user component %s already exists", dst)
+	}
+
+	component.Plugins = append([]userPlugin{}, component.Plugins...)
+	if component.Labels != nil {
+		labels := map[string]string{}
+		for key, value := range component.Labels {
+			labels[key] = value
+		}
+		component.Labels = labels
+	}
+	m.UserComponents[dst] = component
+
+	return m.save()
+}
+
 // DeleteUsercomponent updates metadata deleting an user component
 // XXX: this method isn't thread safe
 func (m *Metadata) DeleteUsercomponent(user string) error {
+	return m.DeleteComponent(ComponentUser, user)
+}
+
+// DeleteComponent updates metadata deleting an instance of a component type
+// XXX: this method isn't thread safe
+func (m *Metadata) DeleteComponent(component string, instance string) error {
 	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	_, ok := m.UserComponents[user]
-	if !ok {
-		return errors.Errorf("user component %s doesn't exist", user)
+	err = m.checkComponentExists(component, instance)
+	if err != nil {
+		return err
 	}
-	delete(m.UserComponents, user)
+	m.deleteInstance(component, instance)
+
 	err = m.save()
 	if err != nil {
 		return err
@@ -255,12 +438,17 @@ func (m *Metadata) DeleteUsercomponent(user string) error {
 
 // ListUsercomponents returns an array with user compoment names for the deployment
 func (m *Metadata) ListUsercomponents() ([]string, error) {
+	return m.ListComponents(ComponentUser)
+}
+
+// ListComponents returns the sorted instance names of a component type
+func (m *Metadata) ListComponents(component string) ([]string, error) {
 	err := m.load()
 	if err != nil {
 		return []string{}, err
 	}
 
-	return m.listUsercomponents()
+	return m.listComponents(component)
 }
 
 // CheckUsercomponent checks if a user component is created
@@ -304,41 +492,48 @@ func (m *Metadata) SetGlobalFlavour(flavour string) error {
 
 // GetUserFlavour returns the Flavour attribute from Metadata for an specified user.
 func (m *Metadata) GetUserFlavour(user string) (string, error) {
+	return m.GetComponentFlavour(ComponentUser, user)
+}
+
+// SetUserFlavour saves the value of given Flavour attribute on Metadata for an specified user.
+// XXX: this method isn't thread safe
+func (m *Metadata) SetUserFlavour(flavour string, user string) error {
+	return m.SetComponentFlavour(flavour, ComponentUser, user)
+}
+
+// GetComponentFlavour returns the Flavour attribute from Metadata for an instance
+// of a component type
+func (m *Metadata) GetComponentFlavour(component string, instance string) (string, error) {
 	err := m.load()
 	if err != nil {
 		return "", err
 	}
 
-	ok, err := m.checkUsercomponent(user)
+	err = m.checkComponentExists(component, instance)
 	if err != nil {
 		return "", err
 	}
-	if !ok {
-		return "", errors.Errorf("user component %s doesn't exist", user)
-	}
 
-	return m.UserComponents[user].Flavour, nil
+	return m.instances(component)[instance].Flavour, nil
 }
 
-// SetUserFlavour saves the value of given Flavour attribute on Metadata for an specified user.
+// SetComponentFlavour saves the value of given Flavour attribute on Metadata for an
+// instance of a component type
 // XXX: this method isn't thread safe
-func (m *Metadata) SetUserFlavour(flavour string, user string) error {
+func (m *Metadata) SetComponentFlavour(flavour string, component string, instance string) error {
 	err := m.load()
 	if err != nil {
 		return err
 	}
 
-	ok, err := m.checkUsercomponent(user)
+	err = m.checkComponentExists(component, instance)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return errors.Errorf("user component %s doesn't exist", user)
-	}
 
-	userComponent := m.UserComponents[user]
-	userComponent.Flavour = flavour
-	m.UserComponents[user] = userComponent
+	c := m.instances(component)[instance]
+	c.Flavour = flavour
+	m.setInstance(component, instance, c)
 
 	err = m.save()
 	if err != nil {
@@ -348,6 +543,76 @@ func (m *Metadata) SetUserFlavour(flavour string, user string) error {
 	return nil
 }
 
+// GetUserLabels returns the labels of an specified user component
+func (m *Metadata) GetUserLabels(user string) (map[string]string, error) {
+	err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	component, ok := m.UserComponents[user]
+	if !ok {
+		return nil, errors.Errorf("user component %s doesn't exist", user)
+	}
+
+	labels := map[string]string{}
+	for key, value := range component.Labels {
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// SetUserLabels adds or replaces the given labels of an user component, and
+// deletes the ones on the remove list.
+// XXX: this method isn't thread safe
+func (m *Metadata) SetUserLabels(user string, labels map[string]string, remove []string) error {
+	err := m.load()
+	if err != nil {
+		return err
+	}
+
+	component, ok := m.UserComponents[user]
+	if !ok {
+		return errors.Errorf("user component %s doesn't exist", user)
+	}
+
+	if component.Labels == nil {
+		component.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		component.Labels[key] = value
+	}
+	for _, key := range remove {
+		delete(component.Labels, key)
+	}
+	if len(component.Labels) == 0 {
+		component.Labels = nil
+	}
+	m.UserComponents[user] = component
+
+	return m.save()
+}
+
+// ListUsercomponentsWithSelector returns the sorted names of the user components
+// whose labels match the selector
+func (m *Metadata) ListUsercomponentsWithSelector(selector Selector) ([]string, error) {
+	err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	users := []string{}
+	for user, component := range m.UserComponents {
+		if selector.Matches(component.Labels) {
+			users = append(users, user)
+		}
+	}
+	sort.Strings(users)
+
+	return users, nil
+}
+
 func newMetadata(fs afero.Fs, varsPath string) *Metadata {
 	return &Metadata{
 		fs:       fs,
@@ -403,14 +668,65 @@ func (m *Metadata) listGlobalPlugins() ([]string, error) {
 }
 
 func (m *Metadata) listUserPlugins(user string) ([]string, error) {
+	return m.listComponentPlugins(ComponentUser, user)
+}
+
+func (m *Metadata) listComponentPlugins(component string, instance string) ([]string, error) {
 	list := []string{}
-	for _, plugin := range m.UserComponents[user].Plugins {
+	for _, plugin := range m.instances(component)[instance].Plugins {
 		list = append(list, plugin.Name)
 	}
 
 	return list, nil
 }
 
+// checkDeleteGlobalPlugin returns an error if the plugin doesn't exist or, unless
+// force is set, if any component instance uses it
+func (m *Metadata) checkDeleteGlobalPlugin(name string, force bool) error {
+	if !m.globalPluginExists(name) {
+		return errors.Errorf("global plugin %s doesn't exist", name)
+	}
+	if force {
+		return nil
+	}
+
+	users := m.listComponentsWithPlugin(name, ComponentUser)
+	if len(users) > 0 {
+		return errors.Errorf("global plugin %s is used by user components %v", name, users)
+	}
+
+	components := []string{}
+	for component := range m.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		instances := m.listComponentsWithPlugin(name, component)
+		if len(instances) > 0 {
+			return errors.Errorf("global plugin %s is used by %s components %v", name, component, instances)
+		}
+	}
+
+	return nil
+}
+
+// checkDeleteComponentPlugin returns an error if the component instance doesn't
+// exist or the plugin isn't added to it
+func (m *Metadata) checkDeleteComponentPlugin(name string, component string, instance string) error {
+	err := m.checkComponentExists(component, instance)
+	if err != nil {
+		return err
+	}
+
+	if !m.globalPluginExists(name) {
+		return errors.Errorf("global plugin %s doesn't exist", name)
+	}
+
+	_, err = m.getComponentPluginIndex(name, component, instance)
+	return err
+}
+
 func (m *Metadata) getGlobalPlugin(name string) (globalPlugin, error) {
 	i, err := m.getGlobalPluginIndex(name)
 	if err != nil {
@@ -444,7 +760,11 @@ func (m *Metadata) globalPluginExists(name string) bool {
 }
 
 func (m *Metadata) userPluginExists(name string, user string) bool {
-	for _, plugin := range m.UserComponents[user].Plugins {
+	return m.componentPluginExists(name, ComponentUser, user)
+}
+
+func (m *Metadata) componentPluginExists(name string, component string, instance string) bool {
+	for _, plugin := range m.instances(component)[instance].Plugins {
 		if plugin.Name == name {
 			return true
 		}
@@ -453,46 +773,104 @@ func (m *Metadata) userPluginExists(name string, user string) bool {
 }
 
 func (m *Metadata) checkUsercomponent(user string) (bool, error) {
-	list, err := m.listUsercomponents()
-	if err != nil {
-		return false, err
-	}
-
-	_, ok := utils.FindString(list, user)
-
+	_, ok := m.UserComponents[user]
 	return ok, nil
 }
 
+// checkComponentExists returns an error if the component instance doesn't exist
+func (m *Metadata) checkComponentExists(component string, instance string) error {
+	if _, ok := m.instances(component)[instance]; !ok {
+		return errors.Errorf("%s doesn't exist", describeComponent(component, instance))
+	}
+	return nil
+}
+
 func (m *Metadata) listUsercomponents() ([]string, error) {
+	return m.listComponents(ComponentUser)
+}
+
+func (m *Metadata) listComponents(component string) ([]string, error) {
 	keys := []string{}
-	for k := range m.UserComponents {
+	for k := range m.instances(component) {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
 	return keys, nil
 }
 
-func (m *Metadata) getUserPlugin(name string, user string) (userPlugin, error) {
-	i, err := m.getUserPluginIndex(name, user)
-	if err != nil {
-		return userPlugin{}, err
+// instances returns the instances of a component type, that may be nil
+func (m *Metadata) instances(component string) map[string]userComponent {
+	if component == ComponentUser {
+		return m.UserComponents
 	}
+	return m.Components[component]
+}
 
-	return m.UserComponents[user].Plugins[i], nil
+func (m *Metadata) setInstance(component string, instance string, c userComponent) {
+	if component == ComponentUser {
+		m.UserComponents[instance] = c
+		return
+	}
+
+	if m.Components == nil {
+		m.Components = map[string]map[string]userComponent{}
+	}
+	if m.Components[component] == nil {
+		m.Components[component] = map[string]userComponent{}
+	}
+	m.Components[component][instance] = c
+}
+
+func (m *Metadata) deleteInstance(component string, instance string) {
+	if component == ComponentUser {
+		delete(m.UserComponents, instance)
+		return
+	}
+
+	delete(m.Components[component], instance)
+	if len(m.Components[component]) == 0 {
+		delete(m.Components, component)
+	}
 }
 
-func (m *Metadata) getUserPluginIndex(name string, user string) (int, error) {
-	for i, plugin := range m.UserComponents[user].Plugins {
+// listComponentsWithPlugin returns the sorted names of the instances of a
+// component type that use the specified plugin
+func (m *Metadata) listComponentsWithPlugin(name string, component string) []string {
+	instances := []string{}
+	for instance := range m.instances(component) {
+		if m.componentPluginExists(name, component, instance) {
+			instances = append(instances, instance)
+		}
+	}
+	sort.Strings(instances)
+
+	return instances
+}
+
+func (m *Metadata) getComponentPluginIndex(name string, component string, instance string) (int, error) {
+	for i, plugin := range m.instances(component)[instance].Plugins {
 		if plugin.Name == name {
 			return i, nil
 		}
 	}
 
-	return -1, errors.Errorf("user plugin %s doesn't exist for user %s", name, user)
+	return -1, errors.Errorf("plugin %s doesn't exist for %s", name, describeComponent(component, instance))
+}
+
+// sortComponentPlugins orders the component instance plugins as global plugins are
+func (m *Metadata) sortComponentPlugins(component string, instance string) {
+	c := m.instances(component)[instance]
+	sort.SliceStable(c.Plugins, func(i, j int) bool {
+		a, _ := m.getGlobalPluginIndex(c.Plugins[i].Name)
+		b, _ := m.getGlobalPluginIndex(c.Plugins[j].Name)
+		return a < b
+	})
+	m.setInstance(component, instance, c)
 }
 
-func (m *Metadata) deleteUserPluginWithIndex(i int, user string) {
-	userComponent := m.UserComponents[user]
-	userComponent.Plugins = append(userComponent.Plugins[:i], userComponent.Plugins[i+1:]...)
-	m.UserComponents[user] = userComponent
+func (m *Metadata) deleteComponentPluginWithIndex(i int, component string, instance string) {
+	c := m.instances(component)[instance]
+	c.Plugins = append(c.Plugins[:i], c.Plugins[i+1:]...)
+	m.setInstance(component, instance, c)
 }
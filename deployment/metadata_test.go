@@ -71,6 +71,40 @@ func TestListGlobalPlugins(t *testing.T) {
 	}
 }
 
+func TestSetGlobalPluginVersion(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = metadata.SetGlobalPluginVersion("plugin2", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := testNewMetadataEmpty(fs)
+	err = loaded.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plugin, err := loaded.getGlobalPlugin("plugin2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plugin.Version != "main" {
+		t.Errorf("Incorrect plugin version.\n\n Expected: %v\n\n Obtained: %v\n", "main", plugin.Version)
+	}
+
+	err = metadata.SetGlobalPluginVersion("plugin3", "main")
+	if err == nil {
+		t.Errorf("Expected error for nonexistent plugin, obtained nil")
+	}
+}
+
 func TestListUserPlugins(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
@@ -91,6 +125,173 @@ func TestListUserPlugins(t *testing.T) {
 	}
 }
 
+func TestDeleteGlobalPluginUsedByUsercomponents(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = metadata.DeleteGlobalPlugin("plugin1", false)
+	expectedError := "global plugin plugin1 is used by user components [user1 user2]"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expectedError, err)
+	}
+
+	expected := []string{"plugin1", "plugin2"}
+	obtained, err := metadata.ListGlobalPlugins()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect global plugin list from metadata.\n\n Expected:\n%s\n\n Obtained:\n%s\n", expected, obtained)
+	}
+}
+
+func TestDeleteGlobalPluginWithForce(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = metadata.DeleteGlobalPlugin("plugin1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"plugin2"}
+	for _, user := range []string{"", "user1", "user2"} {
+		var obtained []string
+		if user == "" {
+			obtained, err = metadata.ListGlobalPlugins()
+		} else {
+			obtained, err = metadata.ListUserPlugins(user)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, obtained) {
+			t.Errorf("Incorrect plugin list from metadata for %s.\n\n Expected:\n%s\n\n Obtained:\n%s\n", user, expected, obtained)
+		}
+	}
+}
+
+func TestRenameUsercomponent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = metadata.RenameUsercomponent("user1", "user3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := testNewMetadataWithData(fs)
+	expected.UserComponents = map[string]userComponent{"user2": testNewUserComponent(), "user3": testNewUserComponent()}
+	if !reflect.DeepEqual(expected, metadata) {
+		t.Errorf("Incorrect metadata.\n\n Expected: %v\n\n Obtained: %v\n", expected, metadata)
+	}
+
+	err = metadata.RenameUsercomponent("user2", "user3")
+	if err == nil {
+		t.Errorf("Renaming to an existing user component must return an error")
+	}
+}
+
+func TestCopyUsercomponent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = metadata.CopyUsercomponent("user1", "user3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := testNewMetadataWithData(fs)
+	expected.UserComponents["user3"] = testNewUserComponent()
+	if !reflect.DeepEqual(expected, metadata) {
+		t.Errorf("Incorrect metadata.\n\n Expected: %v\n\n Obtained: %v\n", expected, metadata)
+	}
+}
+
+func TestSetUserLabels(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = metadata.SetUserLabels("user1", map[string]string{"team": "data", "tier": "prod"}, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = metadata.SetUserLabels("user1", map[string]string{"tier": "dev"}, []string{"team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obtained, err := metadata.GetUserLabels("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"tier": "dev"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect labels.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestListUsercomponentsWithSelector(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	metadata.UserComponents["user3"] = testNewUserComponent()
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"user1", "user3"} {
+		err = metadata.SetUserLabels(user, map[string]string{"team": "data"}, []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	selector, err := ParseSelector("team=data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obtained, err := metadata.ListUsercomponentsWithSelector(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"user1", "user3"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect user components.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
 func testNewMetadataEmpty(fs afero.Fs) Metadata {
 	return Metadata{
 		fs:       fs,
@@ -195,3 +396,57 @@ func testMetadataReferenceJSON() string {
   ]
 }`
 }
+
+func TestCreateComponent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	metadata := testNewMetadataWithData(fs)
+	metadata.UserComponents = map[string]userComponent{}
+	err := metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, instance := range []string{"db2", "db1"} {
+		err = metadata.CreateComponent("database", instance)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = metadata.CreateComponentPlugin("plugin1", "database", "db1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obtainedInstances, err := metadata.ListComponents("database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedInstances := []string{"db1", "db2"}
+	if !reflect.DeepEqual(expectedInstances, obtainedInstances) {
+		t.Errorf("Incorrect components.\n\n Expected: %v\n\n Obtained: %v\n", expectedInstances, obtainedInstances)
+	}
+
+	obtainedPlugins, err := metadata.ListComponentPlugins("database", "db1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPlugins := []string{"plugin1"}
+	if !reflect.DeepEqual(expectedPlugins, obtainedPlugins) {
+		t.Errorf("Incorrect plugins.\n\n Expected: %v\n\n Obtained: %v\n", expectedPlugins, obtainedPlugins)
+	}
+
+	obtainedUsers, err := metadata.ListUsercomponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obtainedUsers) != 0 {
+		t.Errorf("Incorrect user components.\n\n Expected: %v\n\n Obtained: %v\n", []string{}, obtainedUsers)
+	}
+
+	err = metadata.DeleteGlobalPlugin("plugin1", false)
+	expectedError := "global plugin plugin1 is used by database components [db1]"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expectedError, err)
+	}
+}
@@ -0,0 +1,193 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// stateFile is a partial representation of a terraform state file
+type stateFile struct {
+	Resources []stateResource `json:"resources"`
+}
+
+type stateResource struct {
+	Module    string            `json:"module"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Instances []json.RawMessage `json:"instances"`
+}
+
+// rootModule returns the name of the root module call that contains the resource,
+// without instance keys, or empty string for resources of the root module
+func (r *stateResource) rootModule() string {
+	if r.Module == "" {
+		return ""
+	}
+
+	// module names can't contain dots or brackets, unlike instance keys
+	name := strings.TrimPrefix(r.Module, "module.")
+	if i := strings.IndexAny(name, ".["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func (r *stateResource) address() string {
+	address := fmt.Sprintf("%s.%s", r.Type, r.Name)
+	if r.Module != "" {
+		address = r.Module + "." + address
+	}
+	return address
+}
+
+// ListManagedResources returns the addresses of the managed resources tracked
+// on a state file. A missing state file has no resources.
+func (s *State) ListManagedResources(stateFilePath string) ([]string, error) {
+	resources, err := s.listManagedResources(stateFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []string{}
+	for _, resource := range resources {
+		addresses = append(addresses, resource.address())
+	}
+
+	return addresses, nil
+}
+
+func (s *State) listManagedResources(stateFilePath string) ([]stateResource, error) {
+	data, err := afero.ReadFile(s.fs, stateFilePath)
+	if os.IsNotExist(err) {
+		return []stateResource{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read state file %s", stateFilePath)
+	}
+
+	state := stateFile{}
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't unmarshal state file %s", stateFilePath)
+	}
+
+	resources := []stateResource{}
+	for _, resource := range state.Resources {
+		if resource.Mode == "managed" && len(resource.Instances) > 0 {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+// ListManagedResourcesUser returns the addresses of the managed resources tracked
+// on the specified user component state
+func (d *DeploymentImpl) ListManagedResourcesUser(user string) ([]string, error) {
+	return d.ListManagedResourcesComponent(ComponentUser, user)
+}
+
+// ListManagedResourcesComponent returns the addresses of the managed resources tracked
+// on the state of a component instance
+func (d *DeploymentImpl) ListManagedResourcesComponent(component string, instance string) ([]string, error) {
+	return d.State.ListManagedResources(d.State.FilePath(component, instance))
+}
+
+// ListPluginResourcesUser returns the addresses of the managed resources of the user
+// component state declared by the plugin code, that are the resources and modules
+// of the plugin user main files.
+func (d *DeploymentImpl) ListPluginResourcesUser(name string, user string) ([]string, error) {
+	return d.ListPluginResourcesComponent(name, ComponentUser, user)
+}
+
+// ListPluginResourcesComponent returns the addresses of the managed resources of a
+// component instance state declared by the plugin main files of the component type:
+// the resources of the root module declared by the plugin, with any count or
+// for_each instance, and all the resources of the modules called by the plugin,
+// including nested ones.
+func (d *DeploymentImpl) ListPluginResourcesComponent(name string, component string, instance string) ([]string, error) {
+	plugin, err := d.getPluginByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := plugin.ListMainFiles(component)
+	if err != nil {
+		return nil, err
+	}
+
+	declared, err := d.parseDeclarations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := d.State.listManagedResources(d.State.FilePath(component, instance))
+	if err != nil {
+		return nil, err
+	}
+
+	pluginResources := []string{}
+	for _, resource := range resources {
+		if declared.contains(resource) {
+			pluginResources = append(pluginResources, resource.address())
+		}
+	}
+
+	return pluginResources, nil
+}
+
+// declarations holds the resources, by type and name, and the module calls declared
+// on the root module of a component
+type declarations struct {
+	resources map[string]bool
+	modules   map[string]bool
+}
+
+func (decl *declarations) contains(resource stateResource) bool {
+	module := resource.rootModule()
+	if module != "" {
+		return decl.modules[module]
+	}
+	return decl.resources[resource.Type+"."+resource.Name]
+}
+
+// parseDeclarations parses terraform files, returning the resource and module blocks
+// they declare
+func (d *DeploymentImpl) parseDeclarations(files []string) (*declarations, error) {
+	decl := &declarations{
+		resources: map[string]bool{},
+		modules:   map[string]bool{},
+	}
+
+	parser := hclparse.NewParser()
+	for _, file := range files {
+		content, err := afero.ReadFile(d.fs, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't read file %s", file)
+		}
+
+		f, diags := parser.ParseHCL(content, file)
+		if diags.HasErrors() {
+			return nil, errors.Wrapf(diags, "couldn't parse file %s", file)
+		}
+
+		for _, block := range f.Body.(*hclsyntax.Body).Blocks {
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				decl.resources[block.Labels[0]+"."+block.Labels[1]] = true
+			case block.Type == "module" && len(block.Labels) == 1:
+				decl.modules[block.Labels[0]] = true
+			}
+		}
+	}
+
+	return decl, nil
+}
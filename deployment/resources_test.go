@@ -0,0 +1,160 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestListPluginResourcesUser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/deployment/state/user/user1/terraform.tfstate", []byte(testResourcesStateJSON()), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = afero.WriteFile(fs, "/deployment/code/plugins/plugin1/main/user/main.tf", []byte(`
+resource "aws_s3_bucket" "logs" {
+  count  = 2
+  bucket = "logs-${count.index}"
+}
+
+module "network" {
+  source = "../../modules/network"
+}
+
+locals {
+  # blocks inside strings aren't declarations
+  example = <<EOT
+resource "aws_instance" "web" {}
+module "dns" {}
+EOT
+}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		path: "/deployment",
+		State: &State{
+			fs:   fs,
+			path: "/deployment/state",
+		},
+		Plugins: []*CTD{NewCTD(fs, "/deployment/code/plugins/plugin1", "plugin1", "", "")},
+	}
+
+	obtained, err := deploy.ListManagedResourcesUser("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"aws_instance.web", "aws_s3_bucket.logs", "module.network[\"a\"].aws_vpc.this",
+		"module.network[\"a\"].module.subnets[0].aws_subnet.this", "module.dns.aws_route53_zone.this"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect managed resources.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+
+	obtained, err = deploy.ListPluginResourcesUser("plugin1", "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"aws_s3_bucket.logs", "module.network[\"a\"].aws_vpc.this",
+		"module.network[\"a\"].module.subnets[0].aws_subnet.this"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect plugin resources.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestListManagedResourcesWithoutState(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	state := &State{
+		fs:   fs,
+		path: "/deployment/state",
+	}
+
+	obtained, err := state.ListManagedResources(state.FilePathUser("user1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(obtained) != 0 {
+		t.Errorf("Incorrect managed resources.\n\n Expected: %v\n\n Obtained: %v\n", []string{}, obtained)
+	}
+}
+
+func testResourcesStateJSON() string {
+	return `{
+  "version": 4,
+  "resources": [
+    {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [{}]},
+    {"mode": "managed", "type": "aws_s3_bucket", "name": "logs", "instances": [{"index_key": 0}, {"index_key": 1}]},
+    {"mode": "managed", "type": "aws_eip", "name": "empty", "instances": []},
+    {"mode": "data", "type": "aws_ami", "name": "ubuntu", "instances": [{}]},
+    {"module": "module.network[\"a\"]", "mode": "managed", "type": "aws_vpc", "name": "this", "instances": [{}]},
+    {"module": "module.network[\"a\"].module.subnets[0]", "mode": "managed", "type": "aws_subnet", "name": "this", "instances": [{}]},
+    {"module": "module.dns", "mode": "managed", "type": "aws_route53_zone", "name": "this", "instances": [{}]}
+  ]
+}`
+}
+
+func TestDeletePluginGlobalForceWithResources(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/deployment/state/user/user1/terraform.tfstate", []byte(testResourcesStateJSON()), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = afero.WriteFile(fs, "/deployment/code/plugins/plugin1/main/user/main.tf", []byte(`
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := &Metadata{
+		fs:       fs,
+		filePath: "/deployment/variables/metadata.json",
+		Plugins:  []globalPlugin{{Name: "plugin1"}},
+		UserComponents: map[string]userComponent{
+			"user1": {Plugins: []userPlugin{{Name: "plugin1"}}},
+		},
+	}
+	err = metadata.save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		path: "/deployment",
+		Vars: &Vars{Metadata: metadata},
+		State: &State{
+			fs:   fs,
+			path: "/deployment/state",
+		},
+		Plugins: []*CTD{NewCTD(fs, "/deployment/code/plugins/plugin1", "plugin1", "", "")},
+	}
+
+	err = deploy.DeletePluginGlobal("plugin1", true)
+	if err == nil {
+		t.Errorf("Expected error deleting a plugin with resources on user components, obtained nil")
+	}
+
+	obtained, err := deploy.ListPluginsUser("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"plugin1"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect user plugins.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
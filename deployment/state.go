@@ -23,18 +23,24 @@ type State struct {
 
 // FilePathGlobal returns the terraform state file path for the global component.
 func (s *State) FilePathGlobal() string {
-	return filepath.Join(s.path, "global", "terraform.tfstate")
+	return s.FilePath(ComponentGlobal, "")
 }
 
 // FilePathUser returns the terraform state file path for a specified user component.
 func (s *State) FilePathUser(user string) string {
-	return filepath.Join(s.path, "user", user, "terraform.tfstate")
+	return s.FilePath(ComponentUser, user)
 }
 
-// CreateUsercomponent adds a new user component to state,
+// FilePath returns the terraform state file path for an instance of a component
+// type. Instance is ignored for the global component.
+func (s *State) FilePath(component string, instance string) string {
+	return filepath.Join(s.ComponentPath(component, instance), "terraform.tfstate")
+}
+
+// CreateComponent adds a new instance of a component type to state,
 // initializing the directory tree
-func (s *State) CreateUsercomponent(user string) error {
-	err := s.fs.MkdirAll(s.UsercomponentPath(user), 0755)
+func (s *State) CreateComponent(component string, instance string) error {
+	err := s.fs.MkdirAll(s.ComponentPath(component, instance), 0755)
 	if err != nil {
 		return errors.Wrap(err, "couldn't create directory")
 	}
@@ -42,10 +48,10 @@ func (s *State) CreateUsercomponent(user string) error {
 	return nil
 }
 
-// DeleteUsercomponent deletes an user component from state and
+// DeleteComponent deletes an instance of a component type from state and
 // performs a directory cleanup
-func (s *State) DeleteUsercomponent(user string) error {
-	err := s.fs.RemoveAll(s.UsercomponentPath(user))
+func (s *State) DeleteComponent(component string, instance string) error {
+	err := s.fs.RemoveAll(s.ComponentPath(component, instance))
 	if err != nil {
 		return errors.Wrap(err, "couldn't remove dir recursively")
 	}
@@ -53,10 +59,15 @@ func (s *State) DeleteUsercomponent(user string) error {
 	return nil
 }
 
-// UsercomponentPath returns de state directory path for a
-// specified user
-func (s *State) UsercomponentPath(user string) string {
-	return filepath.Join(s.path, "user", user)
+// RenameUsercomponent moves the user component state directory
+func (s *State) RenameUsercomponent(user string, newUser string) error {
+	return utils.DirectoryMove(s.fs, s.ComponentPath(ComponentUser, user), s.ComponentPath(ComponentUser, newUser))
+}
+
+// ComponentPath returns de state directory path for an instance of a
+// component type
+func (s *State) ComponentPath(component string, instance string) string {
+	return filepath.Join(s.path, componentSubpath(component, instance))
 }
 
 // Pull method retrieves terraform state information from git repository
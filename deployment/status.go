@@ -0,0 +1,203 @@
+package deployment
+
+import (
+	"path/filepath"
+
+	"github.com/arodriguezdlc/sonatina/gitw"
+	"github.com/sirupsen/logrus"
+)
+
+// Status is an overview of a deployment: its code, components and the sync
+// state of the storage repository branches
+type Status struct {
+	Name             string            `json:"name"`
+	Code             CodeStatus        `json:"code"`
+	TerraformVersion string            `json:"terraform_version"`
+	Engine           string            `json:"engine"`
+	Flavour          string            `json:"flavour"`
+	Plugins          []CodeStatus      `json:"plugins"`
+	Components       []ComponentStatus `json:"components"`
+	Variables        BranchStatus      `json:"variables"`
+	State            BranchStatus      `json:"state"`
+}
+
+// CodeStatus describes the code of a CTD: its repository, the checked out
+// ref and commit, and the version declared on its manifest
+type CodeStatus struct {
+	Name     string `json:"name,omitempty"`
+	Repo     string `json:"repo"`
+	RepoPath string `json:"repo_path"`
+	Ref      string `json:"ref"`
+	Commit   string `json:"commit"`
+	Version  string `json:"version,omitempty"`
+}
+
+// ComponentStatus describes a component instance and its last commit on the
+// state branch, that is its last apply. LastApply is nil if never applied.
+type ComponentStatus struct {
+	Component string           `json:"component"`
+	Instance  string           `json:"instance,omitempty"`
+	Flavour   string           `json:"flavour"`
+	Plugins   []string         `json:"plugins"`
+	LastApply *gitw.CommitInfo `json:"last_apply"`
+}
+
+// BranchStatus describes the divergence between the local and remote branches of
+// the storage repository, and the uncommitted local changes. Error is set if the
+// remote branch couldn't be compared.
+type BranchStatus struct {
+	Branch      string   `json:"branch"`
+	Ahead       int      `json:"ahead"`
+	Behind      int      `json:"behind"`
+	Uncommitted []string `json:"uncommitted"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Status returns an overview of the deployment. Remote branches of the storage
+// repository are fetched before comparing them if fetch is set.
+func (d *DeploymentImpl) Status(fetch bool) (*Status, error) {
+	err := d.Vars.Metadata.load()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Name:             d.Name,
+		TerraformVersion: d.Vars.Metadata.TerraformVersion,
+		Engine:           d.Engine(),
+		Flavour:          d.Vars.Metadata.Flavour,
+		Plugins:          []CodeStatus{},
+		Components:       []ComponentStatus{},
+	}
+
+	status.Code, err = d.codeStatus(d.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, plugin := range d.Plugins {
+		pluginStatus, err := d.codeStatus(plugin)
+		if err != nil {
+			return nil, err
+		}
+		status.Plugins = append(status.Plugins, pluginStatus)
+	}
+
+	componentTypes, err := d.ListComponentTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, component := range componentTypes {
+		instances := []string{""}
+		if component != ComponentGlobal {
+			instances, err = d.Vars.Metadata.listComponents(component)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, instance := range instances {
+			componentStatus, err := d.componentStatus(component, instance)
+			if err != nil {
+				return nil, err
+			}
+			status.Components = append(status.Components, componentStatus)
+		}
+	}
+
+	status.Variables, err = branchStatus(d.Vars.gitw, varsBranch, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	status.State, err = branchStatus(d.State.gitw, stateBranch, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+func (d *DeploymentImpl) codeStatus(ctd *CTD) (CodeStatus, error) {
+	status := CodeStatus{
+		Name:     ctd.Name,
+		Repo:     ctd.RepoURL,
+		RepoPath: ctd.RepoPath,
+	}
+
+	var err error
+	status.Ref, err = ctd.git.Branch()
+	if err != nil {
+		return status, err
+	}
+
+	status.Commit, err = ctd.Head()
+	if err != nil {
+		return status, err
+	}
+
+	manifest, err := ctd.Manifest()
+	if err != nil {
+		return status, err
+	}
+	status.Version = manifest.Version
+
+	return status, nil
+}
+
+func (d *DeploymentImpl) componentStatus(component string, instance string) (ComponentStatus, error) {
+	status := ComponentStatus{
+		Component: component,
+		Instance:  instance,
+	}
+
+	var err error
+	status.Flavour, err = d.GetFlavourComponent(component, instance)
+	if err != nil {
+		return status, err
+	}
+
+	status.Plugins, err = d.ListPluginsComponent(component, instance)
+	if err != nil {
+		return status, err
+	}
+
+	stateFile, err := filepath.Rel(d.State.path, d.State.FilePath(component, instance))
+	if err != nil {
+		return status, err
+	}
+
+	status.LastApply, err = d.State.gitw.LastCommit(stateFile)
+	if err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+func branchStatus(git *gitw.Command, branch string, fetch bool) (BranchStatus, error) {
+	status := BranchStatus{Branch: branch}
+
+	var err error
+	status.Uncommitted, err = git.ChangedFiles()
+	if err != nil {
+		return status, err
+	}
+
+	if fetch {
+		err = git.Fetch("origin")
+		if err != nil {
+			logrus.WithError(err).WithField("branch", branch).Warning("couldn't fetch storage repository")
+			status.Error = err.Error()
+			return status, nil
+		}
+	}
+
+	status.Ahead, status.Behind, err = git.Divergence("origin", branch)
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	return status, nil
+}
@@ -0,0 +1,394 @@
+package deployment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/arodriguezdlc/sonatina/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// Actions performed by a user components sync
+const (
+	SyncCreate string = "create"
+	SyncUpdate string = "update"
+	SyncDelete string = "delete"
+)
+
+// SyncManifest declares the user components a deployment must have. User components
+// not declared are deleted when synchronizing.
+type SyncManifest struct {
+	UserComponents map[string]UsercomponentSpec `yaml:"usercomponents"`
+}
+
+// UsercomponentSpec declares the flavour, config variables and plugins of an user
+// component. Plugins are declared with their config variables. An empty flavour
+// keeps the current one.
+type UsercomponentSpec struct {
+	Flavour string                            `yaml:"flavour"`
+	Config  map[string]interface{}            `yaml:"config"`
+	Plugins map[string]map[string]interface{} `yaml:"plugins"`
+}
+
+// SyncChange is a change on an user component needed to match a sync manifest.
+// RemovedPlugins are the plugins an updated user component won't use anymore.
+type SyncChange struct {
+	Action         string
+	User           string
+	Details        []string
+	RemovedPlugins []string
+
+	spec UsercomponentSpec
+}
+
+// ReadSyncManifest reads a sync manifest from a yaml file
+func ReadSyncManifest(fs afero.Fs, path string) (*SyncManifest, error) {
+	manifest := &SyncManifest{
+		UserComponents: map[string]UsercomponentSpec{},
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read file %s", path)
+	}
+
+	err = yaml.UnmarshalStrict(data, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse file %s", path)
+	}
+
+	return manifest, nil
+}
+
+// PlanSyncUsercomponents compares the user components with the ones declared on
+// the manifest, returning the changes needed ordered by user component name. The
+// changes are validated, so an error is returned before changing anything.
+func (d *DeploymentImpl) PlanSyncUsercomponents(manifest *SyncManifest) ([]SyncChange, error) {
+	changes := []SyncChange{}
+
+	users, err := d.Vars.Metadata.ListUsercomponents()
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := d.pluginDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if _, ok := manifest.UserComponents[user]; !ok {
+			changes = append(changes, SyncChange{Action: SyncDelete, User: user, Details: []string{}})
+		}
+	}
+
+	for user, spec := range manifest.UserComponents {
+		for plugin := range spec.Plugins {
+			if !d.Vars.Metadata.globalPluginExists(plugin) {
+				return nil, errors.Errorf("plugin %s of user component %s isn't added to the global component", plugin, user)
+			}
+
+			for _, dependency := range dependencies[plugin] {
+				if _, ok := spec.Plugins[dependency]; !ok {
+					return nil, errors.Errorf("plugin %s of user component %s depends on plugin %s, that isn't declared",
+						plugin, user, dependency)
+				}
+			}
+		}
+
+		change := SyncChange{Action: SyncUpdate, User: user, spec: spec}
+		if _, ok := utils.FindString(users, user); !ok {
+			change.Action = SyncCreate
+		}
+
+		change.Details, err = d.diffUsercomponent(user, spec, change.Action == SyncCreate)
+		if err != nil {
+			return nil, err
+		}
+
+		if change.Action == SyncUpdate {
+			change.RemovedPlugins, err = d.removedPluginsUsercomponent(user, spec)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if change.Action == SyncCreate || len(change.Details) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].User < changes[j].User
+	})
+
+	return changes, nil
+}
+
+// SyncUsercomponents applies the changes returned by PlanSyncUsercomponents
+func (d *DeploymentImpl) SyncUsercomponents(changes []SyncChange) error {
+	for _, change := range changes {
+		switch change.Action {
+		case SyncCreate:
+			err := d.CreateUsercomponent(change.User)
+			if err != nil {
+				return err
+			}
+			err = d.updateUsercomponent(change.User, change.spec)
+			if err != nil {
+				return err
+			}
+		case SyncUpdate:
+			err := d.updateUsercomponent(change.User, change.spec)
+			if err != nil {
+				return err
+			}
+		case SyncDelete:
+			err := d.DeleteUsercomponent(change.User)
+			if err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unknown sync action %s", change.Action)
+		}
+	}
+
+	return nil
+}
+
+func (d *DeploymentImpl) diffUsercomponent(user string, spec UsercomponentSpec, create bool) ([]string, error) {
+	details := []string{}
+
+	currentFlavour := d.Vars.Metadata.newUsercomponent().Flavour
+	currentPlugins := []string{}
+	removedPlugins := []string{}
+	if !create {
+		var err error
+		currentFlavour, err = d.Vars.Metadata.GetUserFlavour(user)
+		if err != nil {
+			return nil, err
+		}
+		currentPlugins, err = d.Vars.Metadata.ListUserPlugins(user)
+		if err != nil {
+			return nil, err
+		}
+		removedPlugins, err = d.removedPluginsUsercomponent(user, spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.Flavour != "" && spec.Flavour != currentFlavour {
+		details = append(details, fmt.Sprintf("flavour %s -> %s", currentFlavour, spec.Flavour))
+	}
+
+	for _, plugin := range removedPlugins {
+		details = append(details, fmt.Sprintf("remove plugin %s", plugin))
+	}
+
+	pluginOrder, err := d.Vars.Metadata.ListGlobalPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for _, plugin := range pluginOrder {
+		if _, ok := spec.Plugins[plugin]; !ok {
+			continue
+		}
+		if _, ok := utils.FindString(currentPlugins, plugin); !ok {
+			details = append(details, fmt.Sprintf("add plugin %s", plugin))
+		}
+	}
+
+	variables, err := d.diffConfig(user, "", spec.Config)
+	if err != nil {
+		return nil, err
+	}
+	for _, variable := range variables {
+		details = append(details, fmt.Sprintf("set config %s", variable))
+	}
+
+	for _, plugin := range pluginOrder {
+		variables, err := d.diffConfig(user, plugin, spec.Plugins[plugin])
+		if err != nil {
+			return nil, err
+		}
+		for _, variable := range variables {
+			details = append(details, fmt.Sprintf("set config %s of plugin %s", variable, plugin))
+		}
+	}
+
+	return details, nil
+}
+
+// removedPluginsUsercomponent returns the plugins of an existing user component
+// that aren't declared on its spec
+func (d *DeploymentImpl) removedPluginsUsercomponent(user string, spec UsercomponentSpec) ([]string, error) {
+	currentPlugins, err := d.Vars.Metadata.ListUserPlugins(user)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for _, plugin := range currentPlugins {
+		if _, ok := spec.Plugins[plugin]; !ok {
+			removed = append(removed, plugin)
+		}
+	}
+
+	return removed, nil
+}
+
+// diffConfig returns the ordered names of the config variables which value differs from
+// the one on the user component config file
+func (d *DeploymentImpl) diffConfig(user string, plugin string, config map[string]interface{}) ([]string, error) {
+	variables := []string{}
+
+	content, err := d.readConfigUser(user, plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parseTfvars(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read file %s", d.configFilePathUser(user, plugin))
+	}
+
+	for name, value := range config {
+		rendered, err := renderTfvarsValue(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for config variable %s", name)
+		}
+
+		// Values are compared once parsed, as the file can use any HCL syntax
+		expected, err := parseTfvarsValue(rendered)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for config variable %s", name)
+		}
+
+		current, ok := values[name]
+		if !ok || !current.RawEquals(expected) {
+			variables = append(variables, name)
+		}
+	}
+
+	sort.Strings(variables)
+	return variables, nil
+}
+
+func (d *DeploymentImpl) updateUsercomponent(user string, spec UsercomponentSpec) error {
+	if spec.Flavour != "" {
+		err := d.SetFlavourUser(spec.Flavour, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	pluginOrder, err := d.Vars.Metadata.ListGlobalPlugins()
+	if err != nil {
+		return err
+	}
+
+	// Plugins are removed in reverse order and added following the global component
+	// order, so dependencies are satisfied
+	for i := len(pluginOrder) - 1; i >= 0; i-- {
+		plugin := pluginOrder[i]
+		if _, ok := spec.Plugins[plugin]; ok || !d.Vars.Metadata.userPluginExists(plugin, user) {
+			continue
+		}
+		err = d.DeletePluginUser(plugin, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, plugin := range pluginOrder {
+		if _, ok := spec.Plugins[plugin]; !ok || d.Vars.Metadata.userPluginExists(plugin, user) {
+			continue
+		}
+		err = d.CreatePluginUser(plugin, user, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Config files are initialized from the VTDs before setting variables
+	_, err = d.Vars.Generate(ComponentUser, user)
+	if err != nil {
+		return err
+	}
+
+	err = d.writeConfigUser(user, "", spec.Config)
+	if err != nil {
+		return err
+	}
+
+	for plugin, config := range spec.Plugins {
+		err = d.writeConfigUser(user, plugin, config)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DeploymentImpl) configFilePathUser(user string, plugin string) string {
+	prefix := "base"
+	if plugin != "" {
+		prefix = "plugin_" + plugin
+	}
+	return filepath.Join(d.Vars.UsercomponentPath(user), prefix+"_config.tfvars")
+}
+
+// readConfigUser returns the content of an user component config file, that
+// is empty if the file doesn't exist yet
+func (d *DeploymentImpl) readConfigUser(user string, plugin string) (string, error) {
+	path := d.configFilePathUser(user, plugin)
+
+	data, err := afero.ReadFile(d.fs, path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't read file %s", path)
+	}
+
+	return string(data), nil
+}
+
+func (d *DeploymentImpl) writeConfigUser(user string, plugin string, config map[string]interface{}) error {
+	if len(config) == 0 {
+		return nil
+	}
+
+	content, err := d.readConfigUser(user, plugin)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rendered, err := renderTfvarsValue(config[name])
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for config variable %s", name)
+		}
+		content = setTfvarsValue(content, name, rendered)
+	}
+
+	path := d.configFilePathUser(user, plugin)
+	err = afero.WriteFile(d.fs, path, []byte(content), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't write file %s", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestPlanSyncUsercomponents(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	deploy, err := testSyncNewDeployment(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &SyncManifest{
+		UserComponents: map[string]UsercomponentSpec{
+			"user1": {
+				Flavour: "large",
+				Config:  map[string]interface{}{"size": 2, "name": "user1"},
+				Plugins: map[string]map[string]interface{}{"plugin1": nil},
+			},
+			"user3": {
+				Plugins: map[string]map[string]interface{}{"plugin2": {"retention": 30}},
+			},
+		},
+	}
+
+	changes, err := deploy.PlanSyncUsercomponents(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obtained := []SyncChange{}
+	for _, change := range changes {
+		obtained = append(obtained, SyncChange{Action: change.Action, User: change.User, Details: change.Details, RemovedPlugins: change.RemovedPlugins})
+	}
+	expected := []SyncChange{
+		{Action: SyncUpdate, User: "user1", Details: []string{"flavour default -> large", "remove plugin plugin2", "set config name"}, RemovedPlugins: []string{"plugin2"}},
+		{Action: SyncDelete, User: "user2", Details: []string{}},
+		{Action: SyncCreate, User: "user3", Details: []string{"add plugin plugin2", "set config retention of plugin plugin2"}},
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect sync plan.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestPlanSyncUsercomponentsWithUnknownPlugin(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	deploy, err := testSyncNewDeployment(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &SyncManifest{
+		UserComponents: map[string]UsercomponentSpec{
+			"user1": {Plugins: map[string]map[string]interface{}{"plugin3": nil}},
+		},
+	}
+
+	_, err = deploy.PlanSyncUsercomponents(manifest)
+	if err == nil {
+		t.Errorf("Plugins not added to the global component must return an error")
+	}
+}
+
+func TestPlanSyncUsercomponentsWithHCLConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	deploy, err := testSyncNewDeployment(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "size = 2 # instances\ntags = { team = \"data\" }\nzones = [\n  \"a\",\n  \"b\",\n]\n"
+	err = afero.WriteFile(fs, "/vars/user/user1/base_config.tfvars", []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &SyncManifest{
+		UserComponents: map[string]UsercomponentSpec{
+			"user1": {
+				Config: map[string]interface{}{
+					"size":  2,
+					"tags":  map[interface{}]interface{}{"team": "data"},
+					"zones": []interface{}{"a", "c"},
+				},
+				Plugins: map[string]map[string]interface{}{"plugin1": nil, "plugin2": nil},
+			},
+			"user2": {Plugins: map[string]map[string]interface{}{"plugin1": nil, "plugin2": nil}},
+		},
+	}
+
+	changes, err := deploy.PlanSyncUsercomponents(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obtained := []string{}
+	for _, change := range changes {
+		obtained = append(obtained, change.Details...)
+	}
+	expected := []string{"set config zones"}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect sync plan.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestPlanSyncUsercomponentsWithMissingDependency(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	deploy, err := testSyncNewDeployment(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = afero.WriteFile(fs, "/deployment/code/plugins/plugin2/sonatina.yaml", []byte("dependencies:\n  - name: plugin1\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy.Plugins = []*CTD{
+		NewCTD(fs, "/deployment/code/plugins/plugin1", "plugin1", "", ""),
+		NewCTD(fs, "/deployment/code/plugins/plugin2", "plugin2", "", ""),
+	}
+
+	manifest := &SyncManifest{
+		UserComponents: map[string]UsercomponentSpec{
+			"user1": {Plugins: map[string]map[string]interface{}{"plugin2": nil}},
+		},
+	}
+
+	_, err = deploy.PlanSyncUsercomponents(manifest)
+	if err == nil {
+		t.Errorf("Plugins without their dependencies must return an error")
+	}
+}
+
+func TestReadSyncManifest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	content := `usercomponents:
+  alice:
+    flavour: large
+    plugins:
+      monitoring:
+        retention: 30
+  bob: {}
+`
+	err := afero.WriteFile(fs, "/users.yaml", []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obtained, err := ReadSyncManifest(fs, "/users.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &SyncManifest{
+		UserComponents: map[string]UsercomponentSpec{
+			"alice": {
+				Flavour: "large",
+				Plugins: map[string]map[string]interface{}{"monitoring": {"retention": 30}},
+			},
+			"bob": {},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect sync manifest.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func testSyncNewDeployment(fs afero.Fs) (*DeploymentImpl, error) {
+	metadata := testNewMetadataWithData(fs)
+	err := metadata.save()
+	if err != nil {
+		return nil, err
+	}
+
+	err = afero.WriteFile(fs, "/vars/user/user1/base_config.tfvars", []byte("size = 2\nname = \"test\"\n"), 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deploy := &DeploymentImpl{
+		fs:   fs,
+		path: "/deployment",
+		Vars: &Vars{
+			fs:       fs,
+			path:     "/vars",
+			Metadata: &metadata,
+		},
+	}
+
+	return deploy, nil
+}
@@ -0,0 +1,237 @@
+package deployment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+var tfvarsAssignmentRegexp = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_-]*)\s*=`)
+
+// renderTfvarsValue returns the representation of a value on a tfvars file.
+// JSON syntax is used, that it's a subset of HCL expressions.
+func renderTfvarsValue(value interface{}) (string, error) {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(normalizeYAMLValue(value))
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't render variable value")
+	}
+
+	return strings.TrimSpace(buffer.String()), nil
+}
+
+// normalizeYAMLValue converts maps decoded by yaml, that have interface{} keys,
+// to maps with string keys to be able to encode them as JSON.
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeYAMLValue(item)
+		}
+		return result
+	}
+	return value
+}
+
+// parseTfvars returns the values of the variables assigned on a tfvars content
+func parseTfvars(content string) (map[string]cty.Value, error) {
+	file, diags := hclsyntax.ParseConfig([]byte(content), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "couldn't parse tfvars")
+	}
+
+	attributes, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "couldn't parse tfvars")
+	}
+
+	values := map[string]cty.Value{}
+	for name, attribute := range attributes {
+		// tfvars values are constant, so expressions are evaluated without context
+		value, diags := attribute.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, errors.Wrapf(diags, "couldn't evaluate variable %s", name)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// parseTfvarsValue returns the value of an expression on a tfvars file
+func parseTfvarsValue(expression string) (cty.Value, error) {
+	expr, diags := hclsyntax.ParseExpression([]byte(expression), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, errors.Wrap(diags, "couldn't parse expression")
+	}
+
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, errors.Wrap(diags, "couldn't evaluate expression")
+	}
+
+	return value, nil
+}
+
+// ParseTfvars returns the variables assigned on a tfvars content. Lists and tuples
+// are returned as slices, maps and objects as maps with string keys, and numbers
+// as float64, no matter the HCL syntax used to write them.
+func ParseTfvars(content string) (map[string]interface{}, error) {
+	values, err := parseTfvars(content)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]interface{}{}
+	for name, value := range values {
+		variables[name], err = ctyToGo(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't decode variable %s", name)
+		}
+	}
+
+	return variables, nil
+}
+
+// ctyToGo converts a value parsed from a tfvars file to the go types used by JSON
+func ctyToGo(value cty.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	t := value.Type()
+	switch {
+	case t == cty.String:
+		return value.AsString(), nil
+	case t == cty.Number:
+		var number float64
+		err := gocty.FromCtyValue(value, &number)
+		return number, err
+	case t == cty.Bool:
+		return value.True(), nil
+	case t.IsListType() || t.IsTupleType() || t.IsSetType():
+		result := []interface{}{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			item, err := ctyToGo(element)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	case t.IsMapType() || t.IsObjectType():
+		result := map[string]interface{}{}
+		for it := value.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			item, err := ctyToGo(element)
+			if err != nil {
+				return nil, err
+			}
+			result[key.AsString()] = item
+		}
+		return result, nil
+	}
+
+	return nil, errors.Errorf("unsupported type %s", t.FriendlyName())
+}
+
+// setTfvarsValue returns the tfvars content with the variable assignment replaced by
+// the specified expression, or appended if the variable isn't assigned.
+func setTfvarsValue(content string, name string, value string) string {
+	assignment := fmt.Sprintf("%s = %s", name, value)
+	lines := strings.Split(content, "\n")
+
+	start, end, ok := findTfvarsAssignment(lines, name)
+	if !ok {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + assignment + "\n"
+	}
+
+	result := append([]string{}, lines[:start]...)
+	result = append(result, assignment)
+	result = append(result, lines[end+1:]...)
+	return strings.Join(result, "\n")
+}
+
+// findTfvarsAssignment returns the first and last lines of the top level assignment
+// of a variable, that can span multiple lines with lists or maps.
+func findTfvarsAssignment(lines []string, name string) (int, int, bool) {
+	depth := 0
+	start := -1
+
+	for i, line := range lines {
+		if depth == 0 {
+			match := tfvarsAssignmentRegexp.FindStringSubmatch(line)
+			if match != nil && match[1] == name {
+				start = i
+			}
+		}
+
+		depth += bracketDepth(line)
+
+		if depth <= 0 {
+			depth = 0
+			if start >= 0 {
+				return start, i, true
+			}
+		}
+	}
+
+	if start >= 0 {
+		return start, len(lines) - 1, true
+	}
+	return 0, 0, false
+}
+
+// bracketDepth returns the number of brackets opened and not closed on a line,
+// ignoring strings and comments
+func bracketDepth(line string) int {
+	depth := 0
+	inString := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+		case c == '#' || (c == '/' && i+1 < len(line) && line[i+1] == '/'):
+			return depth
+		case c == '{' || c == '[' || c == '(':
+			depth++
+		case c == '}' || c == ']' || c == ')':
+			depth--
+		}
+	}
+
+	return depth
+}
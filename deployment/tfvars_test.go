@@ -0,0 +1,83 @@
+package deployment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetTfvarsValue(t *testing.T) {
+	content := `# Instance configuration
+size = 1
+tags = {
+  "team" = "platform" # owner {
+}
+name = "test"
+`
+
+	obtained := setTfvarsValue(content, "tags", `{"team":"data"}`)
+	obtained = setTfvarsValue(obtained, "zone", `"eu-west-1a"`)
+	expected := `# Instance configuration
+size = 1
+tags = {"team":"data"}
+name = "test"
+zone = "eu-west-1a"
+`
+
+	if obtained != expected {
+		t.Errorf("Incorrect tfvars content.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestRenderTfvarsValue(t *testing.T) {
+	value := map[interface{}]interface{}{"retention": 30, "zones": []interface{}{"a", "b"}}
+
+	obtained, err := renderTfvarsValue(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"retention":30,"zones":["a","b"]}`
+
+	if obtained != expected {
+		t.Errorf("Incorrect tfvars value.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestParseTfvars(t *testing.T) {
+	content := `# Instance configuration
+size = 2 # note
+enabled = true
+tags = { team = "data", "cost-center" = 10 }
+json = {"team":"data"}
+zones = [
+  "eu-west-1a",
+  "eu-west-1b",
+]
+name = "test"
+empty = null
+`
+
+	obtained, err := ParseTfvars(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"size":    float64(2),
+		"enabled": true,
+		"tags":    map[string]interface{}{"team": "data", "cost-center": float64(10)},
+		"json":    map[string]interface{}{"team": "data"},
+		"zones":   []interface{}{"eu-west-1a", "eu-west-1b"},
+		"name":    "test",
+		"empty":   nil,
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect tfvars variables.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestParseTfvarsInvalid(t *testing.T) {
+	_, err := ParseTfvars("size = var.size\n")
+	if err == nil {
+		t.Errorf("Expressions with references must return an error")
+	}
+}
@@ -10,6 +10,7 @@ import (
 )
 
 const varsBranch string = "variables"
+const lockFileName string = ".terraform.lock.hcl"
 
 // Vars manages the variables branch on storage repo, that includes tfvars files
 // that will be stored on the repository and the metadata file.
@@ -48,15 +49,15 @@ func (v *Vars) Push(message string) error {
 	return nil
 }
 
-// CreateUsercomponent adds a new user component to metadata and
+// CreateComponent adds a new instance of a component type to metadata and
 // initializes the directory tree
-func (v *Vars) CreateUsercomponent(user string) error {
-	err := v.fs.MkdirAll(v.UsercomponentPath(user), 0755)
+func (v *Vars) CreateComponent(component string, instance string) error {
+	err := v.fs.MkdirAll(v.ComponentPath(component, instance), 0755)
 	if err != nil {
 		return errors.Wrap(err, "couldn't create directory")
 	}
 
-	err = v.Metadata.CreateUsercomponent(user)
+	err = v.Metadata.CreateComponent(component, instance)
 	if err != nil {
 		return err
 	}
@@ -64,15 +65,15 @@ func (v *Vars) CreateUsercomponent(user string) error {
 	return nil
 }
 
-// DeleteUsercomponent deletes an user component from metadata
+// DeleteComponent deletes an instance of a component type from metadata
 // and performs a directory cleanup
-func (v *Vars) DeleteUsercomponent(user string) error {
-	err := v.Metadata.DeleteUsercomponent(user)
+func (v *Vars) DeleteComponent(component string, instance string) error {
+	err := v.Metadata.DeleteComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	err = v.fs.RemoveAll(v.UsercomponentPath(user))
+	err = v.fs.RemoveAll(v.ComponentPath(component, instance))
 	if err != nil {
 		return errors.Wrap(err, "couldn't remove dir recursively")
 	}
@@ -80,96 +81,147 @@ func (v *Vars) DeleteUsercomponent(user string) error {
 	return nil
 }
 
-// UsercomponentPath returns de variable directory path for a
-// specified user, that it's on the storage repo.
-func (v *Vars) UsercomponentPath(user string) string {
-	return filepath.Join(v.path, "user", user)
+// RenameUsercomponent renames an user component on metadata, moving its
+// variables directory
+func (v *Vars) RenameUsercomponent(user string, newUser string) error {
+	err := utils.DirectoryMove(v.fs, v.UsercomponentPath(user), v.UsercomponentPath(newUser))
+	if err != nil {
+		return err
+	}
+
+	err = v.Metadata.RenameUsercomponent(user, newUser)
+	if err != nil {
+		// Rollback directory move
+		utils.DirectoryMove(v.fs, v.UsercomponentPath(newUser), v.UsercomponentPath(user))
+		return err
+	}
+
+	return nil
 }
 
-// GenerateGlobal generates vars files to be used on
-// terraform operations. Returns a list of vars files that
-// must be applied in order.
-func (v *Vars) GenerateGlobal() ([]string, error) {
-	varFiles, err := v.copyVTDGlobal(v.deployment.Base.vtd, "base", v.Metadata.Flavour)
+// CopyUsercomponent creates a new user component with the same metadata and
+// config variable files than the source one
+func (v *Vars) CopyUsercomponent(src string, dst string) error {
+	err := v.Metadata.CopyUsercomponent(src, dst)
 	if err != nil {
-		return varFiles, err
+		return err
 	}
 
-	for i, plugin := range v.deployment.Plugins {
-		pluginFiles, err := v.copyVTDGlobal(plugin.vtd, "plugin_"+v.Metadata.Plugins[i].Name, v.Metadata.Flavour)
+	err = v.copyUsercomponentFiles(src, dst)
+	if err != nil {
+		// Rollback metadata and variable files copy
+		v.DeleteComponent(ComponentUser, dst)
+		return err
+	}
+
+	return nil
+}
+
+func (v *Vars) copyUsercomponentFiles(src string, dst string) error {
+	err := v.fs.MkdirAll(v.UsercomponentPath(dst), 0755)
+	if err != nil {
+		return errors.Wrap(err, "couldn't create directory")
+	}
+
+	files, err := afero.Glob(v.fs, filepath.Join(v.UsercomponentPath(src), "*_config.tfvars"))
+	if err != nil {
+		return errors.Wrap(err, "couldn't list config variable files")
+	}
+
+	for _, file := range files {
+		err = utils.FileCopy(v.fs, file, filepath.Join(v.UsercomponentPath(dst), filepath.Base(file)))
 		if err != nil {
-			return varFiles, err
+			return err
 		}
-		varFiles = append(varFiles, pluginFiles...)
 	}
 
-	return varFiles, nil
+	return nil
+}
+
+// UsercomponentPath returns de variable directory path for a
+// specified user, that it's on the storage repo.
+func (v *Vars) UsercomponentPath(user string) string {
+	return v.ComponentPath(ComponentUser, user)
+}
+
+// ComponentPath returns the variable directory path for an instance of a
+// component type, that it's on the storage repo. Instance is ignored for the
+// global component.
+func (v *Vars) ComponentPath(component string, instance string) string {
+	return filepath.Join(v.path, componentSubpath(component, instance))
+}
+
+// LockFilePathGlobal returns the path where the terraform dependency lock file
+// of the global component is stored on the storage repo.
+func (v *Vars) LockFilePathGlobal() string {
+	return v.LockFilePath(ComponentGlobal, "")
 }
 
-// GenerateUser generates vars files to be used on
+// LockFilePath returns the path where the terraform dependency lock file
+// of a component instance is stored on the storage repo.
+func (v *Vars) LockFilePath(component string, instance string) string {
+	return filepath.Join(v.ComponentPath(component, instance), lockFileName)
+}
+
+// Generate generates vars files of a component instance to be used on
 // terraform operations. Returns a list of vars files that
 // must be applied in the returned order.
-func (v *Vars) GenerateUser(user string) ([]string, error) {
-	varFiles, err := v.copyVTDUser(user, v.deployment.Base.vtd, "base", v.Metadata.UserComponents[user].Flavour)
-	if err != nil {
-		return varFiles, err
+func (v *Vars) Generate(component string, instance string) ([]string, error) {
+	flavour := v.Metadata.Flavour
+	if component != ComponentGlobal {
+		flavour = v.Metadata.instances(component)[instance].Flavour
 	}
 
-	userPluginList, err := v.Metadata.ListUserPlugins(user)
+	varFiles, err := v.copyVTD(component, instance, v.deployment.Base.vtd, "base", flavour)
 	if err != nil {
 		return varFiles, err
 	}
 
-	for i, plugin := range v.deployment.Plugins {
-		// Only adds plugin if user has the plugin added.
-		_, ok := utils.FindString(userPluginList, v.Metadata.Plugins[i].Name)
-		if ok {
-			pluginFiles, err := v.copyVTDUser(user, plugin.vtd, "plugin_"+v.Metadata.Plugins[i].Name, v.Metadata.UserComponents[user].Flavour)
-			if err != nil {
-				return varFiles, err
-			}
-			varFiles = append(varFiles, pluginFiles...)
+	for _, plugin := range v.deployment.Plugins {
+		// Only adds plugin if the component instance has the plugin added.
+		if component != ComponentGlobal && !v.Metadata.componentPluginExists(plugin.Name, component, instance) {
+			continue
+		}
+
+		pluginFiles, err := v.copyVTD(component, instance, plugin.vtd, "plugin_"+plugin.Name, flavour)
+		if err != nil {
+			return varFiles, err
 		}
+		varFiles = append(varFiles, pluginFiles...)
 	}
 
 	return varFiles, nil
 }
 
-// GetVariableFilepath returns the variable filepath for a specified kind, plugin and user component
+// GetVariableFilepath returns the variable filepath for a specified kind, plugin and component instance
 // kind: can be config, flavour or static
 // plugin: if it's "", base file (no plugin) is returned
-// user: if it's "", global file (no user component) is returned
-func (v *Vars) GetVariableFilepath(kind string, plugin string, user string) (string, error) {
+// component: component type, instance is ignored for the global component
+func (v *Vars) GetVariableFilepath(kind string, plugin string, component string, instance string) (string, error) {
 	if _, ok := utils.FindString([]string{"config", "flavour", "static"}, kind); !ok {
 		return "", errors.Errorf("Invalid kind %s of variable file", kind)
 	}
 
 	suffix := "_" + kind + ".tfvars"
-	path := v.path
 
-	if user == "" {
-		path = filepath.Join(path, "global")
-	} else {
-		ok, err := v.Metadata.checkUsercomponent(user)
+	if component != ComponentGlobal {
+		err := v.Metadata.checkComponentExists(component, instance)
 		if err != nil {
 			return "", err
 		}
-		if !ok {
-			return "", errors.Errorf("user component %s doesn't exist", user)
-		}
-		path = filepath.Join(path, "user", user)
 	}
+	path := v.ComponentPath(component, instance)
 
 	if plugin == "" {
 		path = filepath.Join(path, "base"+suffix)
 	} else {
-		if user == "" {
+		if component == ComponentGlobal {
 			if !v.Metadata.globalPluginExists(plugin) {
 				return "", errors.Errorf("global plugin %s doesn't exist", plugin)
 			}
 		} else {
-			if !v.Metadata.userPluginExists(plugin, user) {
-				return "", errors.Errorf("user plugin %s doesn't exist", user)
+			if !v.Metadata.componentPluginExists(plugin, component, instance) {
+				return "", errors.Errorf("plugin %s doesn't exist for %s", plugin, describeComponent(component, instance))
 			}
 		}
 		path = filepath.Join(path, "plugin_"+plugin+suffix)
@@ -180,20 +232,20 @@ func (v *Vars) GetVariableFilepath(kind string, plugin string, user string) (str
 
 // Private
 
-func (v *Vars) copyVTDGlobal(vtd *VTD, prefix string, flavour string) ([]string, error) {
+func (v *Vars) copyVTD(component string, instance string, vtd *VTD, prefix string, flavour string) ([]string, error) {
 	varFiles := []string{}
 
-	staticFile, err := v.copyStaticGlobal(vtd, prefix)
+	staticFile, err := v.copyStatic(component, instance, vtd, prefix)
 	if err != nil {
 		return varFiles, err
 	}
 
-	flavourFile, err := v.copyFlavourGlobal(vtd, prefix, flavour)
+	flavourFile, err := v.copyFlavour(component, instance, vtd, prefix, flavour)
 	if err != nil {
 		return varFiles, err
 	}
 
-	configFile, err := v.copyConfigGlobal(vtd, prefix)
+	configFile, err := v.copyConfig(component, instance, vtd, prefix)
 	if err != nil {
 		return varFiles, err
 	}
@@ -202,31 +254,9 @@ func (v *Vars) copyVTDGlobal(vtd *VTD, prefix string, flavour string) ([]string,
 	return varFiles, nil
 }
 
-func (v *Vars) copyVTDUser(user string, vtd *VTD, prefix string, flavour string) ([]string, error) {
-	varFiles := []string{}
-
-	staticFile, err := v.copyStaticUser(user, vtd, prefix)
-	if err != nil {
-		return varFiles, err
-	}
-
-	flavourFile, err := v.copyFlavourUser(user, vtd, prefix, flavour)
-	if err != nil {
-		return varFiles, err
-	}
-
-	configFile, err := v.copyConfigUser(user, vtd, prefix)
-	if err != nil {
-		return varFiles, err
-	}
-
-	varFiles = []string{staticFile, flavourFile, configFile}
-	return varFiles, nil
-}
-
-func (v *Vars) copyConfigGlobal(vtd *VTD, prefix string) (string, error) {
-	src := vtd.config.globalFile()
-	dst := filepath.Join(v.path, "global", prefix+"_config.tfvars")
+func (v *Vars) copyConfig(component string, instance string, vtd *VTD, prefix string) (string, error) {
+	src := vtd.config.file(component)
+	dst := filepath.Join(v.ComponentPath(component, instance), prefix+"_config.tfvars")
 
 	ok, err := afero.Exists(v.fs, dst)
 	if err != nil {
@@ -242,51 +272,9 @@ func (v *Vars) copyConfigGlobal(vtd *VTD, prefix string) (string, error) {
 	return dst, nil
 }
 
-func (v *Vars) copyConfigUser(user string, vtd *VTD, prefix string) (string, error) {
-	src := vtd.config.userFile()
-	dst := filepath.Join(v.path, "user", user, prefix+"_config.tfvars")
-
-	ok, err := afero.Exists(v.fs, dst)
-	if err != nil {
-		return dst, errors.Wrap(err, "couldn't determine if file exists")
-	}
-	if !ok {
-		err := utils.FileCopy(v.fs, src, dst)
-		if err != nil {
-			return dst, err
-		}
-	}
-
-	return dst, nil
-}
-
-func (v *Vars) copyFlavourGlobal(vtd *VTD, prefix string, flavour string) (string, error) {
-	src := vtd.flavour.globalFile(flavour)
-	dst := filepath.Join(v.path, "global", prefix+"_flavour.tfvars")
-
-	err := utils.FileCopy(v.fs, src, dst)
-	if err != nil {
-		return dst, err
-	}
-
-	return dst, nil
-}
-
-func (v *Vars) copyFlavourUser(user string, vtd *VTD, prefix string, flavour string) (string, error) {
-	src := vtd.flavour.userFile(flavour)
-	dst := filepath.Join(v.path, "user", user, prefix+"_flavour.tfvars")
-
-	err := utils.FileCopy(v.fs, src, dst)
-	if err != nil {
-		return dst, err
-	}
-
-	return dst, nil
-}
-
-func (v *Vars) copyStaticGlobal(vtd *VTD, prefix string) (string, error) {
-	src := vtd.static.globalFile()
-	dst := filepath.Join(v.path, "global", prefix+"_static.tfvars")
+func (v *Vars) copyFlavour(component string, instance string, vtd *VTD, prefix string, flavour string) (string, error) {
+	src := vtd.flavour.file(component, flavour)
+	dst := filepath.Join(v.ComponentPath(component, instance), prefix+"_flavour.tfvars")
 
 	err := utils.FileCopy(v.fs, src, dst)
 	if err != nil {
@@ -296,9 +284,9 @@ func (v *Vars) copyStaticGlobal(vtd *VTD, prefix string) (string, error) {
 	return dst, nil
 }
 
-func (v *Vars) copyStaticUser(user string, vtd *VTD, prefix string) (string, error) {
-	src := vtd.static.userFile()
-	dst := filepath.Join(v.path, "user", user, prefix+"_static.tfvars")
+func (v *Vars) copyStatic(component string, instance string, vtd *VTD, prefix string) (string, error) {
+	src := vtd.static.file(component)
+	dst := filepath.Join(v.ComponentPath(component, instance), prefix+"_static.tfvars")
 
 	err := utils.FileCopy(v.fs, src, dst)
 	if err != nil {
@@ -345,13 +333,14 @@ func (d *DeploymentImpl) cloneVars(repoURL string) error {
 	return nil
 }
 
-func (d *DeploymentImpl) createVars(storageRepoURL string, terraformVersion string, codeRepoURL string, codeRepoPath string, flavour string) error {
+func (d *DeploymentImpl) createVars(storageRepoURL string, terraformVersion string, engine string, codeRepoURL string, codeRepoPath string, flavour string) error {
 	vars, err := d.newVars(storageRepoURL)
 	if err != nil {
 		return err
 	}
 
 	vars.Metadata.TerraformVersion = terraformVersion
+	vars.Metadata.Engine = engine
 	vars.Metadata.Repo = codeRepoURL
 	vars.Metadata.RepoPath = codeRepoPath
 	vars.Metadata.Flavour = flavour
@@ -1,8 +1,11 @@
 package deployment
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
 
@@ -44,26 +47,47 @@ func NewVTD(fs afero.Fs, path string) *VTD {
 	}
 }
 
-func (c *config) globalFile() string {
-	return filepath.Join(c.path, "global.tfvars")
+func (c *config) file(component string) string {
+	return filepath.Join(c.path, component+".tfvars")
 }
 
-func (c *config) userFile() string {
-	return filepath.Join(c.path, "user.tfvars")
+func (f *flavour) file(component string, flavour string) string {
+	return filepath.Join(f.path, component, flavour+".tfvars")
 }
 
-func (f *flavour) globalFile(flavour string) string {
-	return filepath.Join(f.path, "global", flavour+".tfvars")
+func (s *static) file(component string) string {
+	return filepath.Join(s.path, component+".tfvars")
 }
 
-func (f *flavour) userFile(flavour string) string {
-	return filepath.Join(f.path, "user", flavour+".tfvars")
-}
+// listFlavours returns the names of the flavour files of any component
+func (v *VTD) listFlavours() ([]string, error) {
+	flavours := []string{}
 
-func (s *static) globalFile() string {
-	return filepath.Join(s.path, "global.tfvars")
-}
+	components, err := afero.ReadDir(v.fs, v.flavour.path)
+	if os.IsNotExist(err) {
+		return flavours, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read directory %s", v.flavour.path)
+	}
+
+	for _, component := range components {
+		if !component.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(v.flavour.path, component.Name())
+		files, err := afero.ReadDir(v.fs, path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't read directory %s", path)
+		}
+
+		for _, file := range files {
+			if !file.IsDir() && strings.HasSuffix(file.Name(), ".tfvars") {
+				flavours = append(flavours, strings.TrimSuffix(file.Name(), ".tfvars"))
+			}
+		}
+	}
 
-func (s *static) userFile() string {
-	return filepath.Join(s.path, "user.tfvars")
+	return flavours, nil
 }
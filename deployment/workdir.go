@@ -1,6 +1,7 @@
 package deployment
 
 import (
+	"os"
 	"path/filepath"
 
 	"github.com/arodriguezdlc/sonatina/utils"
@@ -16,31 +17,27 @@ type Workdir struct {
 }
 
 func (w *Workdir) GenerateGlobal() error {
-	err := w.cleanGlobal()
-	if err != nil {
-		return err
-	}
+	return w.Generate(ComponentGlobal, "")
+}
 
-	err = w.copyMainGlobal()
-	if err != nil {
-		return err
-	}
+func (w *Workdir) GenerateUser(user string) error {
+	return w.Generate(ComponentUser, user)
+}
 
-	err = w.copyModules()
+// Generate combines the main files of the component type from the base CTD and
+// the plugins added to the component instance, and the modules of all of them.
+func (w *Workdir) Generate(component string, instance string) error {
+	err := w.clean(component, instance)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (w *Workdir) GenerateUser(user string) error {
-	err := w.cleanUser(user)
+	err = w.copyMain(component, instance)
 	if err != nil {
 		return err
 	}
 
-	err = w.copyMainUser(user)
+	err = w.copyLockFile(w.deployment.Vars.LockFilePath(component, instance), w.mainPath(component, instance))
 	if err != nil {
 		return err
 	}
@@ -73,31 +70,35 @@ func (d *DeploymentImpl) newWorkdir() error {
 }
 
 func (w *Workdir) mainGlobalPath() string {
-	return filepath.Join(w.path, "main", "global")
+	return w.mainPath(ComponentGlobal, "")
 }
 
 func (w *Workdir) mainUserPath(user string) string {
-	return filepath.Join(w.path, "main", "user", user)
+	return w.mainPath(ComponentUser, user)
+}
+
+func (w *Workdir) mainPath(component string, instance string) string {
+	return filepath.Join(w.path, "main", componentSubpath(component, instance))
 }
 
 func (w *Workdir) modulesPath() string {
 	return filepath.Join(w.path, "modules")
 }
 
-func (w *Workdir) copyMainGlobal() error {
-	fileList, err := w.calculateMainGlobalFileList()
+func (w *Workdir) copyMain(component string, instance string) error {
+	fileList, err := w.calculateMainFileList(component, instance)
 	if err != nil {
 		return err
 	}
 
-	mainPath := w.mainGlobalPath()
+	mainPath := w.mainPath(component, instance)
 	err = w.fs.MkdirAll(mainPath, 0755)
 	if err != nil {
 		return errors.Wrapf(err, "couldn't create directory %s", mainPath)
 	}
 
 	for _, src := range fileList {
-		dst := filepath.Join(w.mainGlobalPath(), filepath.Base(src))
+		dst := filepath.Join(mainPath, filepath.Base(src))
 		err = utils.FileCopy(w.fs, src, dst)
 		if err != nil {
 			return err
@@ -107,27 +108,41 @@ func (w *Workdir) copyMainGlobal() error {
 	return nil
 }
 
-func (w *Workdir) copyMainUser(user string) error {
-	fileList, err := w.calculateMainUserFileList(user)
+// copyLockFile copies the dependency lock file stored on the storage repo to the
+// workdir main path, so terraform init installs the same provider versions. Any
+// previous lock file on the workdir is removed if there isn't one stored.
+func (w *Workdir) copyLockFile(src string, mainPath string) error {
+	dst := filepath.Join(mainPath, lockFileName)
+
+	ok, err := afero.Exists(w.fs, src)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "couldn't determine if file exists")
+	}
+	if !ok {
+		err = w.fs.Remove(dst)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "couldn't remove file %s", dst)
+		}
+		return nil
 	}
 
-	mainPath := w.mainUserPath(user)
-	err = w.fs.MkdirAll(mainPath, 0755)
+	return utils.FileCopy(w.fs, src, dst)
+}
+
+// saveLockFile copies the dependency lock file generated by terraform init on the
+// workdir main path to the storage repo, if it exists.
+func (w *Workdir) saveLockFile(mainPath string, dst string) error {
+	src := filepath.Join(mainPath, lockFileName)
+
+	ok, err := afero.Exists(w.fs, src)
 	if err != nil {
-		return errors.Wrapf(err, "couldn't create directory %s", mainPath)
+		return errors.Wrap(err, "couldn't determine if file exists")
 	}
-
-	for _, src := range fileList {
-		dst := filepath.Join(w.mainUserPath(user), filepath.Base(src))
-		err = utils.FileCopy(w.fs, src, dst)
-		if err != nil {
-			return err
-		}
+	if !ok {
+		return nil
 	}
 
-	return nil
+	return utils.FileCopy(w.fs, src, dst)
 }
 
 func (w *Workdir) copyModules() error {
@@ -158,32 +173,8 @@ func (w *Workdir) copyModules() error {
 	return nil
 }
 
-func (w *Workdir) cleanGlobal() error {
-	path := w.mainGlobalPath()
-
-	files, err := afero.Glob(w.fs, filepath.Join(path, "*.tf"))
-	if err != nil {
-		return errors.Wrap(err, "couldn't list terraform files")
-	}
-
-	for _, file := range files {
-		err = w.fs.Remove(file)
-		if err != nil {
-			return errors.Wrap(err, "couldn't remove file")
-		}
-	}
-
-	path = w.modulesPath()
-	err = w.fs.RemoveAll(path)
-	if err != nil {
-		return errors.Wrap(err, "couldn't remove dir recursively")
-	}
-
-	return nil
-}
-
-func (w *Workdir) cleanUser(user string) error {
-	path := w.mainUserPath(user)
+func (w *Workdir) clean(component string, instance string) error {
+	path := w.mainPath(component, instance)
 
 	files, err := afero.Glob(w.fs, filepath.Join(path, "*.tf"))
 	if err != nil {
@@ -206,63 +197,70 @@ func (w *Workdir) cleanUser(user string) error {
 	return nil
 }
 
-func (w *Workdir) calculateMainGlobalFileList() ([]string, error) {
-	files, err := w.deployment.Base.ListMainGlobalFiles()
+func (w *Workdir) calculateMainFileList(component string, instance string) ([]string, error) {
+	plugins, err := w.deployment.componentPlugins(component, instance)
 	if err != nil {
 		return nil, err
 	}
+	ctds := append([]*CTD{w.deployment.Base}, plugins...)
 
-	for _, plugin := range w.deployment.Plugins {
-		pluginFiles, err := plugin.ListMainGlobalFiles()
-		if err != nil {
-			return nil, err
-		}
-		files = append(files, pluginFiles...)
-	}
-
-	return files, nil
+	return mergeCTDFiles(ctds, func(ctd *CTD) ([]string, error) {
+		return ctd.ListMainFiles(component)
+	})
 }
 
-func (w *Workdir) calculateMainUserFileList(user string) ([]string, error) {
-	files, err := w.deployment.Base.ListMainUserFiles()
-	if err != nil {
-		return nil, err
-	}
+func (w *Workdir) calculateModuleList() ([]string, error) {
+	ctds := append([]*CTD{w.deployment.Base}, w.deployment.Plugins...)
 
-	pluginList, err := w.deployment.Vars.Metadata.listUserPlugins(user)
-	if err != nil {
-		return nil, err
-	}
+	return mergeCTDFiles(ctds, (*CTD).ListModules)
+}
 
-	for _, pluginName := range pluginList {
-		plugin, err := w.deployment.getPluginByName(pluginName)
+// mergeCTDFiles combines the files or modules listed from each CTD, that are copied
+// to the same workdir directory by name. A name already used by a previous CTD is a
+// collision, unless the CTD explicitly overrides it declaring the file path, relative
+// to the CTD root directory, on its manifest.
+func mergeCTDFiles(ctds []*CTD, list func(*CTD) ([]string, error)) ([]string, error) {
+	files := []string{}
+	owners := map[string]int{}
+	ownerCTDs := map[string]*CTD{}
+
+	for _, ctd := range ctds {
+		ctdFiles, err := list(ctd)
 		if err != nil {
 			return nil, err
 		}
 
-		pluginFiles, err := plugin.ListMainUserFiles()
+		manifest, err := ctd.Manifest()
 		if err != nil {
 			return nil, err
 		}
-		files = append(files, pluginFiles...)
-	}
-
-	return files, nil
-}
-
-func (w *Workdir) calculateModuleList() ([]string, error) {
-	modules, err := w.deployment.Base.ListModules()
-	if err != nil {
-		return nil, err
-	}
 
-	for _, plugin := range w.deployment.Plugins {
-		pluginModules, err := plugin.ListModules()
-		if err != nil {
-			return nil, err
+		for _, file := range ctdFiles {
+			name := filepath.Base(file)
+
+			i, ok := owners[name]
+			if !ok {
+				owners[name] = len(files)
+				ownerCTDs[name] = ctd
+				files = append(files, file)
+				continue
+			}
+
+			relativePath, err := filepath.Rel(ctd.path, file)
+			if err != nil {
+				return nil, errors.Wrapf(err, "couldn't get relative path of %s", file)
+			}
+			relativePath = filepath.ToSlash(relativePath)
+
+			if !manifest.overrides(relativePath) {
+				return nil, errors.Errorf("%s of %s collides with %s, declare it on overrides to replace it",
+					relativePath, ctd.description(), ownerCTDs[name].description())
+			}
+
+			files[i] = file
+			ownerCTDs[name] = ctd
 		}
-		modules = append(modules, pluginModules...)
 	}
 
-	return modules, nil
+	return files, nil
 }
@@ -69,6 +69,26 @@ func TestGenerateGlobalWithOverride(t *testing.T) {
 
 }
 
+func TestGenerateGlobalWithCollision(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testWordirCreateDeploymentDirectoriesWithCollision(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workdir, err := testNewWorkdir(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = workdir.GenerateGlobal()
+	expectedError := "main/global/plugin1_file2.tf of plugin plugin2 collides with plugin plugin1, declare it on overrides to replace it"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expectedError, err)
+	}
+}
+
 func TestGenerateUser(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
@@ -145,6 +165,106 @@ func TestGenerateUserWithOverride(t *testing.T) {
 	}
 }
 
+func TestGenerateComponent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testWordirCreateDeploymentDirectories(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ctd := range []string{"base", "plugin1", "plugin2"} {
+		path := filepath.Join("deployment", "base")
+		if ctd != "base" {
+			path = filepath.Join("deployment", "plugins", ctd)
+		}
+		err = afero.WriteFile(fs, filepath.Join(path, "main", "database", ctd+"_db.tf"), []byte(""), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	workdir, err := testNewWorkdir(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workdir.deployment.Vars.Metadata.Components = map[string]map[string]userComponent{
+		"database": {"db1": userComponent{Plugins: []userPlugin{{Name: "plugin2"}}}},
+	}
+
+	err = workdir.Generate("database", "db1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFileList := []string{
+		filepath.Join("deployment", "workdir", "main", "database", "db1", "base_db.tf"),
+		filepath.Join("deployment", "workdir", "main", "database", "db1", "plugin2_db.tf"),
+	}
+	obtainedFileList, err := utils.FileListRecursivelyWithoutDirs(fs, filepath.Join("deployment", "workdir", "main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedFileList, obtainedFileList) {
+		t.Errorf("Incorrect file list.\n\n Expected: %v\n\n Obtained: %v\n", expectedFileList, obtainedFileList)
+	}
+}
+
+func TestGenerateGlobalWithLockFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testWordirCreateDeploymentDirectories(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workdir, err := testNewWorkdir(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workdir.deployment.Vars.path = filepath.Join("deployment", "variables")
+
+	expectedContent := "provider \"registry.terraform.io/hashicorp/null\" {}"
+	err = utils.NewFileWithContentIfNotExist(fs, workdir.deployment.Vars.LockFilePathGlobal(), expectedContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = workdir.GenerateGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockFilePath := filepath.Join("deployment", "workdir", "main", "global", ".terraform.lock.hcl")
+	obtainedContent, err := afero.ReadFile(fs, lockFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedContent, string(obtainedContent)) {
+		t.Errorf("Incorrect lock file content.\n\n Expected: %v\n\n Obtained: %v\n", expectedContent, string(obtainedContent))
+	}
+
+	err = afero.WriteFile(fs, lockFilePath, []byte("updated"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = workdir.saveLockFile(workdir.mainGlobalPath(), workdir.deployment.Vars.LockFilePathGlobal())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	savedContent, err := afero.ReadFile(fs, workdir.deployment.Vars.LockFilePathGlobal())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual("updated", string(savedContent)) {
+		t.Errorf("Incorrect saved lock file content.\n\n Expected: %v\n\n Obtained: %v\n", "updated", string(savedContent))
+	}
+}
+
 func testNewWorkdir(fs afero.Fs) (*Workdir, error) {
 
 	base := NewCTD(fs, filepath.Join("deployment", "base"), "", "", "")
@@ -208,9 +328,33 @@ func testWordirCreateDeploymentDirectoriesWithOverride(fs afero.Fs) error {
 		}
 	}
 
+	manifest := `overrides:
+  - main/global/file1.tf
+  - main/global/file2.tf
+  - main/user/file1.tf
+  - main/user/file2.tf
+  - modules/module1
+  - modules/module2
+`
+	for _, path := range basepaths[1:] {
+		err := afero.WriteFile(fs, filepath.Join(path, manifestFileName), []byte(manifest), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func testWordirCreateDeploymentDirectoriesWithCollision(fs afero.Fs) error {
+	err := testWordirCreateDeploymentDirectories(fs)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, filepath.Join("deployment", "plugins", "plugin2", "main", "global", "plugin1_file2.tf"), []byte(""), 0644)
+}
+
 func testWorkdirGlobalExpectedFiles() []string {
 	return []string{
 		filepath.Join("deployment", "workdir", "main", "global", "base_file1.tf"),
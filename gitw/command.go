@@ -1,6 +1,10 @@
 package gitw
 
 import (
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
@@ -183,8 +187,244 @@ func (c *Command) Push(remote string, branch string) error {
 	return nil
 }
 
+// Head returns the hash of the commit HEAD points to
+func (c *Command) Head() (string, error) {
+	repo, err := c.open()
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get HEAD reference")
+	}
+
+	return ref.Hash().String(), nil
+}
+
+// ResetHard executes a `git reset --hard <commit>` equivalent
+func (c *Command) ResetHard(commit string) error {
+	worktree, err := c.worktree()
+	if err != nil {
+		return err
+	}
+
+	err = worktree.Reset(&git.ResetOptions{
+		Commit: plumbing.NewHash(commit),
+		Mode:   git.HardReset,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "couldn't reset to %s", commit)
+	}
+
+	return nil
+}
+
+// CheckoutBranch executes a `git checkout <branch>` equivalent over an existing branch
+func (c *Command) CheckoutBranch(branch string) error {
+	worktree, err := c.worktree()
+	if err != nil {
+		return err
+	}
+
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "couldn't checkout branch %s", branch)
+	}
+
+	return nil
+}
+
+// RemoteURL returns the first URL of the specified remote
+func (c *Command) RemoteURL(name string) (string, error) {
+	repo, err := c.open()
+	if err != nil {
+		return "", err
+	}
+
+	remote, err := repo.Remote(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't get remote %s", name)
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", errors.Errorf("remote %s has no url", name)
+	}
+
+	return urls[0], nil
+}
+
+// CommitInfo describes a commit
+type CommitInfo struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+}
+
+// Branch returns the name of the branch HEAD points to
+func (c *Command) Branch() (string, error) {
+	repo, err := c.open()
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get HEAD reference")
+	}
+
+	return ref.Name().Short(), nil
+}
+
+// Fetch executes a `git fetch <remote>` equivalent
+func (c *Command) Fetch(remote string) error {
+	repo, err := c.open()
+	if err != nil {
+		return err
+	}
+
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: remote,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return errors.Wrapf(err, "couldn't fetch from %s", remote)
+	}
+
+	return nil
+}
+
+// Divergence returns the number of commits the local branch is ahead and behind
+// the remote tracking branch, like `git rev-list --left-right --count`. Remote
+// tracking branch is updated by Fetch.
+func (c *Command) Divergence(remote string, branch string) (int, int, error) {
+	repo, err := c.open()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	local, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "couldn't get branch %s", branch)
+	}
+
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, branch), true)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "couldn't get remote branch %s/%s", remote, branch)
+	}
+
+	localCommits, err := c.ancestors(repo, local.Hash())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	remoteCommits, err := c.ancestors(repo, remoteRef.Hash())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ahead := 0
+	for hash := range localCommits {
+		if !remoteCommits[hash] {
+			ahead++
+		}
+	}
+
+	behind := 0
+	for hash := range remoteCommits {
+		if !localCommits[hash] {
+			behind++
+		}
+	}
+
+	return ahead, behind, nil
+}
+
+// ChangedFiles returns the sorted paths of the files with uncommitted changes,
+// like `git status --porcelain`
+func (c *Command) ChangedFiles() ([]string, error) {
+	worktree, err := c.worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get worktree status")
+	}
+
+	files := []string{}
+	for file, fileStatus := range status {
+		if fileStatus.Staging == git.Unmodified && fileStatus.Worktree == git.Unmodified {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// LastCommit returns the last commit from HEAD that modified the file, like
+// `git log -1 -- <path>`. The path is relative to the repository root. Returns
+// nil if the file has never been committed.
+func (c *Command) LastCommit(path string) (*CommitInfo, error) {
+	repo, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+
+	path = filepath.ToSlash(path)
+	iter, err := repo.Log(&git.LogOptions{
+		FileName: &path,
+		Order:    git.LogOrderCommitterTime,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get log of %s", path)
+	}
+	defer iter.Close()
+
+	commit, err := iter.Next()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get log of %s", path)
+	}
+
+	return &CommitInfo{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		Date:    commit.Author.When,
+		Message: strings.TrimSpace(commit.Message),
+	}, nil
+}
+
 // Private
 
+func (c *Command) ancestors(repo *git.Repository, from plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get log from %s", from)
+	}
+
+	commits := map[plumbing.Hash]bool{}
+	err = iter.ForEach(func(commit *object.Commit) error {
+		commits[commit.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get log from %s", from)
+	}
+
+	return commits, nil
+}
+
 func (c *Command) open() (*git.Repository, error) {
 	repo, err := git.PlainOpen(c.path)
 	if err != nil {
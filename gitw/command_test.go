@@ -1,3 +1,113 @@
 package gitw
 
-// TODO: testing
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestDivergenceAndLastCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs := afero.NewOsFs()
+	origin, _ := NewCommand(fs, filepath.Join(dir, "origin"))
+	local, _ := NewCommand(fs, filepath.Join(dir, "local"))
+
+	err = origin.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = testCommitFile(origin, "global/terraform.tfstate", "initial state")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	branch, err := origin.Branch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = local.CloneBranch(origin.path, branch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = testCommitFile(local, "user/user1/terraform.tfstate", "apply user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = testCommitFile(origin, "other.txt", "remote change")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(local.path, "uncommitted.txt"), []byte("data"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = local.Fetch("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ahead, behind, err := local.Divergence("origin", branch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ahead != 1 || behind != 1 {
+		t.Errorf("Incorrect divergence.\n\n Expected: %v\n\n Obtained: %v\n", []int{1, 1}, []int{ahead, behind})
+	}
+
+	changedFiles, err := local.ChangedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFiles := []string{"uncommitted.txt"}
+	if !reflect.DeepEqual(expectedFiles, changedFiles) {
+		t.Errorf("Incorrect changed files.\n\n Expected: %v\n\n Obtained: %v\n", expectedFiles, changedFiles)
+	}
+
+	commit, err := local.LastCommit(filepath.Join("global", "terraform.tfstate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit == nil || commit.Message != "initial state" {
+		t.Errorf("Incorrect last commit.\n\n Expected: %v\n\n Obtained: %v\n", "initial state", commit)
+	}
+
+	commit, err = local.LastCommit(filepath.Join("user", "user2", "terraform.tfstate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit != nil {
+		t.Errorf("Incorrect last commit.\n\n Expected: %v\n\n Obtained: %v\n", nil, commit)
+	}
+}
+
+func testCommitFile(c *Command, file string, message string) error {
+	path := filepath.Join(c.path, file)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, []byte(message), 0644)
+	if err != nil {
+		return err
+	}
+
+	err = c.AddGlob(".")
+	if err != nil {
+		return err
+	}
+
+	return c.Commit(message)
+}
@@ -12,10 +12,13 @@ import (
 type Manager interface {
 	List() ([]string, error)
 	Get(name string) (deployment.Deployment, error)
+	StorageRepoURI(name string) (string, error)
 	Create(name string, storageRepoURI string, codeRepoURI string, codeRepoPath string,
-		terraformVersion string, flavour string) error
+		terraformVersion string, engine string, flavour string) error
 	Clone(name string, storageRepoURI string) error
 	Delete(name string) error
+	Diagnose(name string, fix bool) ([]deployment.Problem, error)
+	DiagnoseRegistry(fix bool) ([]deployment.Problem, error)
 }
 
 // Single manager on sonatina execution. It's initialized at program start,
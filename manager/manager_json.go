@@ -5,8 +5,10 @@ package manager
 
 import (
 	"encoding/json"
+	"fmt"
 	"path/filepath"
 
+	"github.com/arodriguezdlc/sonatina/gitw"
 	"github.com/arodriguezdlc/sonatina/utils"
 	"github.com/pkg/errors"
 
@@ -101,6 +103,21 @@ func (m *managerJSON) Get(name string) (deployment.Deployment, error) {
 	return deploy, nil
 }
 
+// StorageRepoURI returns the storage repository URI of the deployment
+func (m *managerJSON) StorageRepoURI(name string) (string, error) {
+	dm, err := m.read()
+	if err != nil {
+		return "", err
+	}
+
+	di, ok := dm[name]
+	if !ok {
+		return "", DeploymentDoNotExistsError{name}
+	}
+
+	return di.StorageRepoURI, nil
+}
+
 // Clone downloads deployment information from the storage repo initializes all the
 // background file structure of a sonatina deployment
 func (m *managerJSON) Clone(name string, storageRepoURI string) error {
@@ -130,7 +147,7 @@ func (m *managerJSON) Clone(name string, storageRepoURI string) error {
 }
 
 func (m *managerJSON) Create(name string, storageRepoURI string, codeRepoURI string, codeRepoPath string,
-	terraformVersion string, flavour string) error {
+	terraformVersion string, engine string, flavour string) error {
 
 	dm, err := m.read()
 	if err != nil {
@@ -142,7 +159,7 @@ func (m *managerJSON) Create(name string, storageRepoURI string, codeRepoURI str
 	}
 
 	err = deployment.Create(name, storageRepoURI, codeRepoURI, codeRepoPath,
-		terraformVersion, flavour, m.fs, filepath.Join(m.deploymentsPath, name))
+		terraformVersion, engine, flavour, m.fs, filepath.Join(m.deploymentsPath, name))
 	if err != nil {
 		return err
 	}
@@ -175,6 +192,76 @@ func (m *managerJSON) Delete(name string) error {
 	return err
 }
 
+// Diagnose checks the local storage of the deployment, repairing it if fix is set
+func (m *managerJSON) Diagnose(name string, fix bool) ([]deployment.Problem, error) {
+	storageRepoURI, err := m.StorageRepoURI(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployment.Diagnose(name, storageRepoURI, m.fs, filepath.Join(m.deploymentsPath, name), fix)
+}
+
+// DiagnoseRegistry reports the directories on deployments path that aren't on the
+// deployments file. If fix is set, they're added using the remote of their variables
+// repository as storage repo URI.
+func (m *managerJSON) DiagnoseRegistry(fix bool) ([]deployment.Problem, error) {
+	dm, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := afero.ReadDir(m.fs, m.deploymentsPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read directory %s", m.deploymentsPath)
+	}
+
+	problems := []deployment.Problem{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if _, ok := dm[name]; ok || !entry.IsDir() {
+			continue
+		}
+
+		problem := deployment.Problem{
+			Deployment: name,
+			Check:      deployment.CheckRegistry,
+			Message:    fmt.Sprintf("directory %s isn't on deployments file", filepath.Join(m.deploymentsPath, name)),
+		}
+
+		storageRepoURI, err := m.storageRepoURIFromDirectory(name)
+		if err != nil {
+			problem.Message = fmt.Sprintf("%s: %v", problem.Message, err)
+		} else {
+			problem.Fixable = true
+		}
+
+		if fix && problem.Fixable {
+			m.add(name, deploymentItem{StorageRepoURI: storageRepoURI}, &dm)
+			err = m.save(dm)
+			if err != nil {
+				return nil, err
+			}
+			problem.Fixed = true
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// storageRepoURIFromDirectory returns the origin remote of the variables repository
+// of a deployment directory
+func (m *managerJSON) storageRepoURIFromDirectory(name string) (string, error) {
+	git, err := gitw.NewCommand(m.fs, filepath.Join(m.deploymentsPath, name, "variables"))
+	if err != nil {
+		return "", err
+	}
+
+	return git.RemoteURL("origin")
+}
+
 func (m *managerJSON) delete(name string) error {
 	deploys, err := m.read()
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/spf13/afero"
 )
 
@@ -60,6 +61,73 @@ func TestListWithEmptyFile(t *testing.T) {
 	}
 }
 
+func TestStorageRepoURI(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testWriteCorrectDeployFile(fs)
+	if err != nil {
+		t.Error(err)
+	}
+
+	m, err := newManagerJSON(fs, "/", "deployments.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := m.StorageRepoURI("deploy2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "git@test.com/deploy2storage"
+	if result != expected {
+		t.Errorf("Incorrect storage repo uri. Expected: %s, Obtained: %s", expected, result)
+	}
+
+	_, err = m.StorageRepoURI("deploy3")
+	if _, ok := err.(DeploymentDoNotExistsError); !ok {
+		t.Errorf("Incorrect error. Expected: %v, Obtained: %v", DeploymentDoNotExistsError{"deploy3"}, err)
+	}
+}
+
+func TestDiagnoseRegistry(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := testWriteCorrectDeployFile(fs)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, dir := range []string{"/deploy1", "/orphan"} {
+		err = fs.MkdirAll(dir, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := newManagerJSON(fs, "/", "deployments.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := m.DiagnoseRegistry(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) != 1 {
+		t.Fatalf("Incorrect number of problems. Expected: %d, Obtained: %d", 1, len(problems))
+	}
+	if problems[0].Deployment != "orphan" || problems[0].Check != deployment.CheckRegistry {
+		t.Errorf("Incorrect problem. Expected: %s %s, Obtained: %s %s",
+			"orphan", deployment.CheckRegistry, problems[0].Deployment, problems[0].Check)
+	}
+	// orphan directory has no variables repository to get the storage repo uri from
+	if problems[0].Fixable || problems[0].Fixed {
+		t.Errorf("Incorrect problem, it shouldn't be fixable: %v", problems[0])
+	}
+}
+
 // TODO: use mocks
 // func TestGetDeploy(t *testing.T) {
 // 	fs := afero.NewMemMapFs()
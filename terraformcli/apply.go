@@ -1,23 +1,30 @@
 package terraformcli
 
 import (
+	"context"
 	"os/exec"
 
 	"github.com/sirupsen/logrus"
 )
 
-func (t *Terraform) Apply(path string, varFiles []string, stateFile string) error {
+func (t *Terraform) Apply(ctx context.Context, path string, varFiles []string, stateFile string) error {
 	args := []string{}
 	args = append(args, "apply")
 	args = append(args, t.applyDefaultOptions().array()...)
 	args = append(args, t.varFilesOptions(varFiles).array()...)
 	args = append(args, t.stateFileOption(stateFile).render())
+	if t.supportsJSONOutput() {
+		args = append(args, t.jsonOption().render())
+	}
 	logrus.WithField("args", args).Info("executing terraform command")
 
 	cmd := exec.Command(t.BinaryPath(), args...)
 	cmd.Dir = path
 
-	return t.runPrintingAll(cmd)
+	if t.supportsJSONOutput() {
+		return t.runPrintingJSON(ctx, cmd, "apply")
+	}
+	return t.runPrintingAll(ctx, cmd, "apply")
 }
 
 func (t *Terraform) applyDefaultOptions() *options {
@@ -0,0 +1,50 @@
+package terraformcli
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/utils"
+	"github.com/pkg/errors"
+)
+
+// releaseArchs are the os_arch combinations published on terraform releases
+var releaseArchs = []string{
+	"darwin_amd64",
+	"darwin_arm64",
+	"freebsd_386",
+	"freebsd_amd64",
+	"freebsd_arm",
+	"linux_386",
+	"linux_amd64",
+	"linux_arm",
+	"linux_arm64",
+	"openbsd_386",
+	"openbsd_amd64",
+	"solaris_amd64",
+	"windows_386",
+	"windows_amd64",
+}
+
+// HostArch returns the os_arch release name that matches the host where
+// sonatina is running.
+func HostArch() (string, error) {
+	return releaseArch(runtime.GOOS, runtime.GOARCH)
+}
+
+// releaseArch maps go os and architecture names to the naming used on
+// terraform releases.
+func releaseArch(goos string, goarch string) (string, error) {
+	arch := goos + "_" + goarch
+
+	_, ok := utils.FindString(releaseArchs, arch)
+	if !ok {
+		return "", errors.Errorf("unsupported platform %s, configure BinaryArch to override it", arch)
+	}
+
+	return arch, nil
+}
+
+func isWindowsArch(arch string) bool {
+	return strings.HasPrefix(arch, "windows_")
+}
@@ -0,0 +1,25 @@
+package terraformcli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReleaseArch(t *testing.T) {
+	expected := "linux_amd64"
+	obtained, err := releaseArch("linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect release arch.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestReleaseArchUnsupported(t *testing.T) {
+	_, err := releaseArch("plan9", "amd64")
+	if err == nil {
+		t.Errorf("Expected error for unsupported platform, obtained nil")
+	}
+}
@@ -2,51 +2,323 @@ package terraformcli
 
 import (
 	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/arodriguezdlc/sonatina/utils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
+// jsonOutputMinVersion is the first terraform version with machine readable UI output
+const jsonOutputMinVersion string = "0.15.3"
+
+// refreshOnlyMinVersion is the first terraform version with refresh-only plans
+const refreshOnlyMinVersion string = "0.15.4"
+
 type binary struct {
 	fs   afero.Fs
 	path string
 
+	engine  Engine
 	version string
 	arch    string
+
+	// localPath is a binary supplied by the user, that is never downloaded
+	localPath string
 }
 
-// BinaryPath returns the path of the terraform binary
+// BinaryPath returns the path of the terraform binary. Downloaded binaries are
+// cached on a directory per arch.
 func (b *binary) BinaryPath() string {
-	return filepath.Join(b.path, fmt.Sprintf("terraform_%s_%s", b.version, b.arch))
+	if b.localPath != "" {
+		return b.localPath
+	}
+
+	name := fmt.Sprintf("%s_%s", b.engine.BinaryName, b.version)
+	if isWindowsArch(b.arch) {
+		name += ".exe"
+	}
+	return filepath.Join(b.archPath(), name)
 }
 
-// getBinary downloads a terraform binary from Hashicorp official release page.
+// getBinary downloads a terraform binary from the engine official release page,
+// verifying the zip file against the signed checksums before unpacking it. The
+// release files are kept to verify the binary again when it's reused.
 func (b *binary) getBinary() error {
-	url := b.terraformDownloadURL()
+	if b.localPath != "" {
+		return errors.Errorf("local binary %s doesn't exist", b.localPath)
+	}
+
+	checksums, signature, err := b.getChecksums()
+	if err != nil {
+		return err
+	}
+
+	url := b.engine.downloadURL(b.version, b.arch)
 
 	zipFile, err := b.downloadZip(url)
+	if zipFile != "" {
+		defer b.fs.Remove(zipFile)
+	}
 	if err != nil {
 		return err
 	}
 
+	err = b.verifyZip(zipFile, path.Base(url), parseChecksums(checksums))
+	if err != nil {
+		return err
+	}
+
+	err = b.fs.MkdirAll(b.releasePath(), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't create directory %s", b.releasePath())
+	}
+
 	err = b.uncompressZip(zipFile, b.BinaryPath())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return b.saveRelease(zipFile, checksums, signature)
 }
 
-// XXX: a sha check would be great. For now, only check if binary exists
+// checkBinary returns true if the binary exists and it matches the release it was
+// unpacked from, whose checksums signature is verified again. Local binaries are
+// checked to be the engine and version used by the deployment.
 func (b *binary) checkBinary() (bool, error) {
-	return afero.Exists(b.fs, b.BinaryPath())
+	ok, err := afero.Exists(b.fs, b.BinaryPath())
+	if err != nil || !ok {
+		return ok, err
+	}
+	if b.localPath != "" {
+		return true, b.checkLocalVersion()
+	}
+
+	logger := logrus.WithField("binary", b.BinaryPath())
+
+	checksums, signature, zipFile := b.releaseFiles()
+	for _, file := range []string{checksums, signature, zipFile} {
+		ok, err = afero.Exists(b.fs, file)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			logger.Warning("cached binary has no release files, it will be downloaded again")
+			return false, nil
+		}
+	}
+
+	content, err := afero.ReadFile(b.fs, checksums)
+	if err != nil {
+		return false, errors.Wrapf(err, "couldn't read file %s", checksums)
+	}
+	signatureContent, err := afero.ReadFile(b.fs, signature)
+	if err != nil {
+		return false, errors.Wrapf(err, "couldn't read file %s", signature)
+	}
+
+	err = b.verifySignature(content, signatureContent)
+	if err != nil {
+		logger.WithError(err).Warning("cached release verification failed, it will be downloaded again")
+		return false, nil
+	}
+
+	err = b.verifyZip(zipFile, filepath.Base(zipFile), parseChecksums(content))
+	if err != nil {
+		logger.WithError(err).Warning("cached release verification failed, it will be downloaded again")
+		return false, nil
+	}
+
+	expected, err := b.zipBinarySHA256(zipFile)
+	if err != nil {
+		return false, err
+	}
+	obtained, err := fileSHA256(b.fs, b.BinaryPath())
+	if err != nil {
+		return false, err
+	}
+
+	if expected != obtained {
+		logger.Warning("cached binary checksum mismatch, it will be downloaded again")
+		return false, nil
+	}
+
+	return true, nil
 }
 
-func (b *binary) downloadZip(url string) (string, error) {
+// checkLocalVersion returns an error if the `version` output of the local binary
+// doesn't match the engine and version used by the deployment
+func (b *binary) checkLocalVersion() error {
+	output, err := exec.Command(b.localPath, "version").Output()
+	if err != nil {
+		return errors.Wrapf(err, "couldn't get version of local binary %s", b.localPath)
+	}
+
+	engine, version, err := parseVersionOutput(output)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't get version of local binary %s", b.localPath)
+	}
+
+	if !strings.EqualFold(engine, b.engine.Name) || version != b.version {
+		return errors.Errorf("local binary %s is %s %s, but deployment uses %s %s",
+			b.localPath, engine, version, b.engine.Name, b.version)
+	}
+
+	return nil
+}
+
+// getChecksums downloads the checksums file of the release and its signature,
+// verifying it with the engine signing key. The signature is only downloaded if
+// the engine has a signing key.
+func (b *binary) getChecksums() ([]byte, []byte, error) {
+	content, err := b.fetch(b.engine.checksumURL(b.version, b.arch))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signature := []byte{}
+	if b.engine.SigningKey != "" {
+		signature, err = b.fetch(b.engine.signatureURL(b.version, b.arch))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = b.verifySignature(content, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, signature, nil
+}
+
+// verifySignature verifies the checksums signature with the engine signing key,
+// that is optional: without key, only the checksums are verified.
+func (b *binary) verifySignature(content []byte, signature []byte) error {
+	if b.engine.SigningKey == "" {
+		logrus.WithField("engine", b.engine.Name).Debug("no signing key configured, checksums signature isn't verified")
+		return nil
+	}
+
+	return verifySignature(b.engine.SigningKey, content, signature)
+}
+
+func (b *binary) verifyZip(zipFilePath string, zipName string, checksums map[string]string) error {
+	expected, ok := checksums[zipName]
+	if !ok {
+		return errors.Errorf("couldn't find checksum for %s", zipName)
+	}
+
+	obtained, err := fileSHA256(b.fs, zipFilePath)
+	if err != nil {
+		return err
+	}
+
+	if expected != obtained {
+		return errors.Errorf("checksum mismatch for %s: expected %s, obtained %s", zipName, expected, obtained)
+	}
+
+	return nil
+}
+
+// saveRelease keeps the verified zip file and the signed checksums on the release
+// directory, used to verify the binary when it's reused from cache.
+func (b *binary) saveRelease(zipFilePath string, checksums []byte, signature []byte) error {
+	checksumsPath, signaturePath, zipPath := b.releaseFiles()
+
+	err := afero.WriteFile(b.fs, checksumsPath, checksums, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't write file %s", checksumsPath)
+	}
+
+	err = afero.WriteFile(b.fs, signaturePath, signature, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't write file %s", signaturePath)
+	}
+
+	err = b.fs.Rename(zipFilePath, zipPath)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't move file %s to %s", zipFilePath, zipPath)
+	}
+
+	return nil
+}
+
+// supportsJSONOutput returns true if the binary supports machine readable UI
+// output (-json flag) on apply and destroy commands.
+func (b *binary) supportsJSONOutput() bool {
+	if b.engine.Name == EngineOpenTofu {
+		return true
+	}
+
+	result, err := utils.CompareVersions(b.version, jsonOutputMinVersion)
+	if err != nil {
+		return false
+	}
+	return result >= 0
+}
+
+// supportsRefreshOnly returns true if the binary supports refresh-only plans
+func (b *binary) supportsRefreshOnly() bool {
+	if b.engine.Name == EngineOpenTofu {
+		return true
+	}
+
+	result, err := utils.CompareVersions(b.version, refreshOnlyMinVersion)
+	if err != nil {
+		return false
+	}
+	return result >= 0
+}
+
+// parseVersionOutput returns the engine name and version from the first line of
+// `terraform version` output, like "Terraform v1.5.7"
+func parseVersionOutput(output []byte) (string, string, error) {
+	line := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)[0]
+	fields := strings.Fields(line)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "v") {
+		return "", "", errors.Errorf("unexpected version output %q", line)
+	}
+
+	return fields[0], strings.TrimPrefix(fields[1], "v"), nil
+}
+
+func (b *binary) archPath() string {
+	return filepath.Join(b.path, b.arch)
+}
+
+// binaryFileName returns the binary name inside the release zip
+func (b *binary) binaryFileName() string {
+	if isWindowsArch(b.arch) {
+		return b.engine.BinaryName + ".exe"
+	}
+	return b.engine.BinaryName
+}
+
+// releasePath returns the directory where the files of downloaded releases are kept
+func (b *binary) releasePath() string {
+	return filepath.Join(b.archPath(), "releases")
+}
+
+// releaseFiles returns the paths of the checksums file, its signature and the zip
+// file of the release on the release directory
+func (b *binary) releaseFiles() (string, string, string) {
+	base := b.releasePath()
+	return filepath.Join(base, path.Base(b.engine.checksumURL(b.version, b.arch))),
+		filepath.Join(base, path.Base(b.engine.signatureURL(b.version, b.arch))),
+		filepath.Join(base, path.Base(b.engine.downloadURL(b.version, b.arch)))
+}
+
+func (b *binary) downloadZip(location string) (string, error) {
 	filePath := ""
 	file, err := afero.TempFile(b.fs, b.path, "terraform_zip_")
 	if err != nil {
@@ -55,15 +327,71 @@ func (b *binary) downloadZip(url string) (string, error) {
 	defer file.Close()
 
 	filePath = file.Name()
-	err = utils.HTTPDownloadFile(b.fs, file, url)
+	if isRemote(location) {
+		err = utils.HTTPDownloadFile(b.fs, file, location)
+		if err != nil {
+			return filePath, err
+		}
+		return filePath, nil
+	}
+
+	source, err := b.fs.Open(localLocation(location))
 	if err != nil {
-		return filePath, err
+		return filePath, errors.Wrapf(err, "couldn't open file %s", location)
+	}
+	defer source.Close()
+
+	_, err = io.Copy(file, source)
+	if err != nil {
+		return filePath, errors.Wrapf(err, "couldn't copy file %s", location)
 	}
 
 	return filePath, nil
 }
 
+// fetch returns the content of a release file, that can be an URL or a local
+// path when a local mirror is used.
+func (b *binary) fetch(location string) ([]byte, error) {
+	if isRemote(location) {
+		return utils.HTTPGet(location)
+	}
+
+	content, err := afero.ReadFile(b.fs, localLocation(location))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read file %s", location)
+	}
+	return content, nil
+}
+
 func (b *binary) uncompressZip(zipFilePath string, binaryFilePath string) error {
+	return b.readZipBinary(zipFilePath, func(file io.Reader) error {
+		binaryFile, err := b.fs.OpenFile(binaryFilePath, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0755)
+		if err != nil {
+			return err
+		}
+		defer binaryFile.Close()
+
+		_, err = io.Copy(binaryFile, file)
+		return err
+	})
+}
+
+// zipBinarySHA256 returns the hex encoded sha256 of the binary inside a release zip
+func (b *binary) zipBinarySHA256(zipFilePath string) (string, error) {
+	hash := sha256.New()
+	err := b.readZipBinary(zipFilePath, func(file io.Reader) error {
+		_, err := io.Copy(hash, file)
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't read binary from %s", zipFilePath)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readZipBinary calls read with the content of the binary inside a release zip
+func (b *binary) readZipBinary(zipFilePath string, read func(io.Reader) error) error {
 	zipFile, err := b.fs.Open(zipFilePath)
 	if err != nil {
 		return err
@@ -80,25 +408,36 @@ func (b *binary) uncompressZip(zipFilePath string, binaryFilePath string) error
 		return err
 	}
 
-	binaryFile, err := b.fs.OpenFile(binaryFilePath, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0755)
+	zipEntry, err := b.findBinaryInZip(reader)
 	if err != nil {
 		return err
 	}
-	defer binaryFile.Close()
 
-	file, err := reader.File[0].Open()
+	file, err := zipEntry.Open()
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	_, err = io.Copy(binaryFile, file)
-	if err != nil {
-		return err
+	return read(file)
+}
+
+// findBinaryInZip looks for the engine binary between the zip entries, that
+// can also include other files like licenses or readmes.
+func (b *binary) findBinaryInZip(reader *zip.Reader) (*zip.File, error) {
+	for _, file := range reader.File {
+		if filepath.Base(file.Name) == b.binaryFileName() {
+			return file, nil
+		}
 	}
 
-	return nil
+	return nil, errors.Errorf("couldn't find %s binary in zip file", b.binaryFileName())
+}
+
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
 }
 
-func (b *binary) terraformDownloadURL() string {
-	return fmt.Sprintf("https://releases.hashicorp.com/terraform/%s/terraform_%s_%s.zip", b.version, b.version, b.arch)
+func localLocation(location string) string {
+	return strings.TrimPrefix(location, "file://")
 }
@@ -1,31 +1,93 @@
 package terraformcli
 
 import (
+	"archive/zip"
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/spf13/afero"
 )
 
-// This test requires internet access and actually downloads a fully terraform zip.
-// We should avoid this.
+const testBinaryContent string = "fake terraform binary"
+
 func TestGetBinary(t *testing.T) {
-	// Hardcoded test values
 	version := "0.12.24"
-	arch := "darwin_amd64"
-	expectedSHA := "dfce338efc62080ad02b14c3d389db0d8e33664994373f840ba4001b1c860392"
-	expectedBinaryPath := filepath.Join("terraform", "terraform_0.12.24_darwin_amd64")
+	arch := "linux_amd64"
+	expectedBinaryPath := filepath.Join("terraform", "linux_amd64", "terraform_0.12.24")
+
+	signer, armoredKey := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(release)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, armoredKey), version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = binary.getBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terraformBinary, err := afero.ReadFile(fs, expectedBinaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(testBinaryContent, string(terraformBinary)) {
+		t.Errorf("Incorrect binary content.\n\n Expected: %v\n\n Obtained: %v\n", testBinaryContent, string(terraformBinary))
+	}
+
+	ok, err := binary.checkBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("Expected downloaded binary to be verified")
+	}
+}
+
+func TestGetBinaryWindows(t *testing.T) {
+	version := "0.12.24"
+	arch := "windows_amd64"
+	expectedBinaryPath := filepath.Join("terraform", "windows_amd64", "terraform_0.12.24.exe")
+
+	signer, armoredKey := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(release)
+	defer ts.Close()
 
 	fs := afero.NewMemMapFs()
 
-	binary, err := testNewBinary(fs, filepath.Join("terraform"), version, arch)
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, armoredKey), version, arch)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	if !reflect.DeepEqual(expectedBinaryPath, binary.BinaryPath()) {
+		t.Errorf("Incorrect binary path.\n\n Expected: %v\n\n Obtained: %v\n", expectedBinaryPath, binary.BinaryPath())
+	}
+
 	err = binary.getBinary()
 	if err != nil {
 		t.Fatal(err)
@@ -35,18 +97,281 @@ func TestGetBinary(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	obtainedSHAbytes := sha256.Sum256(terraformBinary)
-	obtainedSHA := hex.EncodeToString(obtainedSHAbytes[:])
 
-	if !reflect.DeepEqual(expectedSHA, obtainedSHA) {
-		t.Errorf("Incorrect file list.\n\n Expected: %v\n\n Obtained: %v\n", expectedSHA, obtainedSHA)
+	if !reflect.DeepEqual(testBinaryContent, string(terraformBinary)) {
+		t.Errorf("Incorrect binary content.\n\n Expected: %v\n\n Obtained: %v\n", testBinaryContent, string(terraformBinary))
+	}
+}
+
+func TestGetBinaryWithoutSigningKey(t *testing.T) {
+	version := "0.12.24"
+	arch := "linux_amd64"
+
+	signer, _ := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(release)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, ""), version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Checksums are verified without signature if there is no signing key
+	err = binary.getBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := binary.checkBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("Binary downloaded without signing key must be reused from cache")
 	}
 }
 
-func testNewBinary(fs afero.Fs, path string, version string, arch string) (binary, error) {
+func TestVerifySignatureWithExpiredKey(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour)
+	config := &packet.Config{
+		Algorithm:       packet.PubKeyAlgoEdDSA,
+		KeyLifetimeSecs: 3600,
+		Time:            func() time.Time { return created },
+	}
+
+	entity, err := openpgp.NewEntity("sonatina", "test", "sonatina@example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armoredKey, err := testArmoredPublicKey(entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("checksums")
+	signature := &bytes.Buffer{}
+	err = openpgp.DetachSign(signature, entity, bytes.NewReader(content), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verifySignature(armoredKey, content, signature.Bytes())
+	expected := "signing key expired, set TerraformSigningKey with a current key"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expected, err)
+	}
+}
+
+func TestGetBinaryWithInvalidSignature(t *testing.T) {
+	version := "0.12.24"
+	arch := "linux_amd64"
+
+	signer, _ := testNewSigner(t)
+	_, armoredKey := testNewSigner(t)
+
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(release)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, armoredKey), version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = binary.getBinary()
+	if err == nil {
+		t.Errorf("Expected error verifying signature with an unknown key, obtained nil")
+	}
+}
+
+func TestGetBinaryWithChecksumMismatch(t *testing.T) {
+	version := "0.12.24"
+	arch := "linux_amd64"
+
+	signer, armoredKey := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release[fmt.Sprintf("/terraform/%s/terraform_%s_%s.zip", version, version, arch)] = []byte("tampered zip")
+	ts := httptest.NewServer(release)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, armoredKey), version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = binary.getBinary()
+	if err == nil {
+		t.Errorf("Expected checksum mismatch error, obtained nil")
+	}
+
+	ok, err := afero.Exists(fs, binary.BinaryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Binary must not be unpacked when checksum doesn't match")
+	}
+}
+
+func TestCheckBinaryModified(t *testing.T) {
+	version := "0.12.24"
+	arch := "linux_amd64"
+
+	signer, armoredKey := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(release)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, armoredKey), version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = binary.getBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = afero.WriteFile(fs, binary.BinaryPath(), []byte("modified binary"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := binary.checkBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Expected modified binary to fail verification")
+	}
+}
+
+func TestCheckBinaryModifiedWithRelease(t *testing.T) {
+	version := "0.12.24"
+	arch := "linux_amd64"
+
+	signer, armoredKey := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(release)
+	defer ts.Close()
+
+	fs := afero.NewMemMapFs()
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), testNewEngine(ts.URL, armoredKey), version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = binary.getBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// binary and cached release are replaced consistently, but without a valid signature
+	tampered, err := testNewReleaseWithContent(version, arch, nil, "modified binary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums, _, zipFile := binary.releaseFiles()
+	prefix := fmt.Sprintf("/terraform/%s/", version)
+	for file, key := range map[string]string{
+		checksums: prefix + fmt.Sprintf("terraform_%s_SHA256SUMS", version),
+		zipFile:   prefix + fmt.Sprintf("terraform_%s_%s.zip", version, arch),
+	} {
+		err = afero.WriteFile(fs, file, tampered[key], 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = afero.WriteFile(fs, binary.BinaryPath(), []byte("modified binary"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := binary.checkBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Expected binary with tampered release files to fail verification")
+	}
+}
+
+func TestGetBinaryFromLocalMirror(t *testing.T) {
+	version := "0.12.24"
+	arch := "linux_amd64"
+
+	signer, armoredKey := testNewSigner(t)
+	release, err := testNewRelease(version, arch, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := afero.NewMemMapFs()
+	for path, content := range release {
+		err = afero.WriteFile(fs, filepath.Join("/mirror", path), content, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine, err := GetEngine(EngineTerraform)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine = engine.WithMirror("/mirror")
+	engine.SigningKey = armoredKey
+
+	binary, err := testNewBinary(fs, filepath.Join("terraform"), engine, version, arch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = binary.getBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terraformBinary, err := afero.ReadFile(fs, binary.BinaryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(testBinaryContent, string(terraformBinary)) {
+		t.Errorf("Incorrect binary content.\n\n Expected: %v\n\n Obtained: %v\n", testBinaryContent, string(terraformBinary))
+	}
+}
+
+func testNewBinary(fs afero.Fs, path string, engine Engine, version string, arch string) (binary, error) {
 	binary := binary{
 		fs:      fs,
 		path:    path,
+		engine:  engine,
 		version: version,
 		arch:    arch,
 	}
@@ -58,3 +383,140 @@ func testNewBinary(fs afero.Fs, path string, version string, arch string) (binar
 
 	return binary, nil
 }
+
+func testNewEngine(baseURL string, signingKey string) Engine {
+	return Engine{
+		Name:         EngineTerraform,
+		BinaryName:   "terraform",
+		DownloadURL:  baseURL + "/terraform/{version}/terraform_{version}_{arch}.zip",
+		ChecksumURL:  baseURL + "/terraform/{version}/terraform_{version}_SHA256SUMS",
+		SignatureURL: baseURL + "/terraform/{version}/terraform_{version}_SHA256SUMS.sig",
+		SigningKey:   signingKey,
+	}
+}
+
+// testRelease serves fake release artifacts, using the url path as key
+type testRelease map[string][]byte
+
+func (r testRelease) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	content, ok := r[req.URL.Path]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	w.Write(content)
+}
+
+// testNewRelease generates a zip with a fake binary and its checksums file. If signer
+// isn't nil, checksums file is also signed.
+func testNewRelease(version string, arch string, signer *openpgp.Entity) (testRelease, error) {
+	return testNewReleaseWithContent(version, arch, signer, testBinaryContent)
+}
+
+func testNewReleaseWithContent(version string, arch string, signer *openpgp.Entity, content string) (testRelease, error) {
+	zipName := fmt.Sprintf("terraform_%s_%s.zip", version, arch)
+	prefix := fmt.Sprintf("/terraform/%s/", version)
+
+	binaryName := "terraform"
+	if isWindowsArch(arch) {
+		binaryName += ".exe"
+	}
+
+	zipBuffer := &bytes.Buffer{}
+	writer := zip.NewWriter(zipBuffer)
+	// binary isn't the first entry, like on real releases
+	entries := [][2]string{{"LICENSE.txt", "license"}, {binaryName, content}}
+	for _, entry := range entries {
+		file, err := writer.Create(entry[0])
+		if err != nil {
+			return nil, err
+		}
+		_, err = file.Write([]byte(entry[1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(zipBuffer.Bytes())
+	checksums := fmt.Sprintf("%s  %s\n%s  terraform_%s_other_arch.zip\n",
+		hex.EncodeToString(checksum[:]), zipName, hex.EncodeToString(make([]byte, 32)), version)
+
+	release := testRelease{
+		prefix + zipName: zipBuffer.Bytes(),
+		prefix + fmt.Sprintf("terraform_%s_SHA256SUMS", version): []byte(checksums),
+	}
+
+	if signer != nil {
+		signature := &bytes.Buffer{}
+		err = openpgp.DetachSign(signature, signer, bytes.NewReader([]byte(checksums)), nil)
+		if err != nil {
+			return nil, err
+		}
+		release[prefix+fmt.Sprintf("terraform_%s_SHA256SUMS.sig", version)] = signature.Bytes()
+	}
+
+	return release, nil
+}
+
+// testNewSigner generates a signing entity, returning it with its armored public key
+func testNewSigner(t *testing.T) (*openpgp.Entity, string) {
+	entity, err := openpgp.NewEntity("sonatina", "test", "sonatina@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	armoredKey, err := testArmoredPublicKey(entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entity, armoredKey
+}
+
+func testArmoredPublicKey(entity *openpgp.Entity) (string, error) {
+	buffer := &bytes.Buffer{}
+	writer, err := armor.Encode(buffer, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", err
+	}
+
+	err = entity.Serialize(writer)
+	if err != nil {
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func TestParseVersionOutput(t *testing.T) {
+	cases := map[string][]string{
+		"Terraform v0.13.5\n":                             {"Terraform", "0.13.5"},
+		"Terraform v1.5.7\non linux_amd64\n":              {"Terraform", "1.5.7"},
+		"OpenTofu v1.6.0\non linux_amd64\n+ provider x\n": {"OpenTofu", "1.6.0"},
+	}
+
+	for output, expected := range cases {
+		engine, version, err := parseVersionOutput([]byte(output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		obtained := []string{engine, version}
+		if !reflect.DeepEqual(expected, obtained) {
+			t.Errorf("Incorrect version.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+		}
+	}
+
+	_, _, err := parseVersionOutput([]byte("unknown\n"))
+	if err == nil {
+		t.Errorf("Unexpected version output must return an error")
+	}
+}
@@ -0,0 +1,75 @@
+//go:build !windows
+// +build !windows
+
+package terraformcli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestCheckBinaryLocalVersion(t *testing.T) {
+	localBinary := testNewVersionBinary(t, "Terraform v0.13.5")
+	defer os.RemoveAll(filepath.Dir(localBinary))
+
+	binary := binary{
+		fs:        afero.NewOsFs(),
+		path:      "/terraform",
+		engine:    engines[EngineTerraform],
+		version:   "0.13.5",
+		arch:      "linux_amd64",
+		localPath: localBinary,
+	}
+
+	ok, err := binary.checkBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("Local binary with deployment version must be valid")
+	}
+}
+
+func TestCheckBinaryLocalVersionMismatch(t *testing.T) {
+	localBinary := testNewVersionBinary(t, "Terraform v1.5.7")
+	defer os.RemoveAll(filepath.Dir(localBinary))
+
+	for _, engine := range []string{EngineTerraform, EngineOpenTofu} {
+		binary := binary{
+			fs:        afero.NewOsFs(),
+			path:      "/terraform",
+			engine:    engines[engine],
+			version:   "0.13.5",
+			arch:      "linux_amd64",
+			localPath: localBinary,
+		}
+
+		_, err := binary.checkBinary()
+		expected := "is Terraform 1.5.7, but deployment uses " + engine + " 0.13.5"
+		if err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expected, err)
+		}
+	}
+}
+
+// testNewVersionBinary returns a binary on a temporary directory that prints
+// the version line when executed
+func testNewVersionBinary(t *testing.T, version string) string {
+	path, err := ioutil.TempDir("", "terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := filepath.Join(path, "terraform")
+	err = ioutil.WriteFile(binary, []byte("#!/bin/sh\necho '"+version+"'\necho 'on linux_amd64'\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return binary
+}
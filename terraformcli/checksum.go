@@ -0,0 +1,68 @@
+package terraformcli
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// parseChecksums parses the content of a SHA256SUMS file, returning a map with
+// the filename as key and its hex encoded sha256 as value.
+func parseChecksums(content []byte) map[string]string {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+
+	return checksums
+}
+
+// verifySignature checks that signature is a valid detached signature of content,
+// made by the armoredKey owner.
+func verifySignature(armoredKey string, content []byte, signature []byte) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		return errors.Wrap(err, "couldn't read signing key")
+	}
+
+	_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(content), bytes.NewReader(signature), nil)
+	if errors.Is(err, pgperrors.ErrKeyExpired) {
+		return errors.New("signing key expired, set TerraformSigningKey with a current key")
+	}
+	if err != nil {
+		return errors.Wrap(err, "invalid checksums signature")
+	}
+
+	return nil
+}
+
+// fileSHA256 returns the hex encoded sha256 of a file
+func fileSHA256(fs afero.Fs, path string) (string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't open file %s", path)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't calculate sha256 of file %s", path)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -1,23 +1,259 @@
 package terraformcli
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 )
 
+// maxTailLines is the number of output lines kept to report errors
+const maxTailLines int = 50
+
+// maxLogFiles is the number of terraform run logs kept on the log path, older
+// ones are removed
+const maxLogFiles int = 100
+
 type command struct {
+	fs      afero.Fs
+	logPath string
+
+	// out receives the terraform output printed to the user, os.Stdout if it isn't set
+	out io.Writer
+}
+
+// SetLogPath configures the directory where the output of each terraform run
+// is saved. Output isn't saved if it's not configured.
+func (c *command) SetLogPath(path string) {
+	c.logPath = path
+}
+
+// SetOutput configures where the terraform output printed to the user is written,
+// so standard output can be kept for machine readable results.
+func (c *command) SetOutput(out io.Writer) {
+	c.out = out
+}
+
+func (c *command) output() io.Writer {
+	if c.out == nil {
+		return os.Stdout
+	}
+	return c.out
+}
+
+// runPrintingAll executes the command streaming its output to the terminal and to
+// a log file for the run. Errors include the last diagnostics printed by terraform.
+func (c *command) runPrintingAll(ctx context.Context, cmd *exec.Cmd, operation string) error {
+	logFile, logFilePath, err := c.openLogFile(operation)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	tail := newTailWriter(maxTailLines)
+	cmd.Stdout = io.MultiWriter(c.output(), logFile, tail)
+	cmd.Stderr = io.MultiWriter(os.Stderr, logFile, tail)
+
+	err = c.run(ctx, cmd)
+	if err != nil {
+		return c.runError(err, operation, tail.diagnostics(), logFilePath)
+	}
+
+	return nil
+}
+
+// runPrintingJSON executes a command that prints terraform machine readable UI
+// output. Raw output is saved to the run log file, while human readable messages
+// with a resource progress summary are printed to terminal.
+func (c *command) runPrintingJSON(ctx context.Context, cmd *exec.Cmd, operation string) error {
+	logFile, logFilePath, err := c.openLogFile(operation)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	ui := newJSONUI(c.output())
+	tail := newTailWriter(maxTailLines)
+	cmd.Stdout = io.MultiWriter(logFile, ui)
+	cmd.Stderr = io.MultiWriter(os.Stderr, logFile, tail)
+
+	err = c.run(ctx, cmd)
+	ui.Flush()
+	if err != nil {
+		diagnostics := ui.diagnostics()
+		if len(diagnostics) == 0 {
+			diagnostics = tail.diagnostics()
+		}
+		return c.runError(err, operation, diagnostics, logFilePath)
+	}
+
+	return nil
+}
+
+// runCapturingOutput executes the command returning its standard output, that
+// isn't printed. Standard error is printed as usual.
+func (c *command) runCapturingOutput(ctx context.Context, cmd *exec.Cmd, operation string) ([]byte, error) {
+	var stdout bytes.Buffer
+	tail := newTailWriter(maxTailLines)
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+
+	err := c.run(ctx, cmd)
+	if err != nil {
+		return nil, c.runError(err, operation, tail.diagnostics(), "")
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// run executes the command until it finishes. If the context is done before,
+// terraform is interrupted so it can stop gracefully and release the state. Any
+// signal received meanwhile is also forwarded, so a second interrupt aborts
+// terraform as when running it directly.
+func (c *command) run(ctx context.Context, cmd *exec.Cmd) error {
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "terraform not executed")
+	}
+
+	setProcessGroup(cmd)
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	logrus.WithError(ctx.Err()).Warn("interrupting terraform")
+	fmt.Fprintln(os.Stderr, "Interrupting terraform, waiting for it to stop gracefully...")
+	err = interruptProcess(cmd.Process)
+	if err != nil {
+		logrus.WithError(err).Error("couldn't interrupt terraform")
+	}
+
+	for {
+		select {
+		case err = <-done:
+			if err != nil {
+				return errors.Wrap(ctx.Err(), "terraform interrupted")
+			}
+			return nil
+		case sig := <-signals:
+			logrus.WithField("signal", sig).Warn("forwarding signal to terraform")
+			err = interruptProcess(cmd.Process)
+			if err != nil {
+				logrus.WithError(err).Error("couldn't interrupt terraform")
+			}
+		}
+	}
 }
 
-func (c *command) runPrintingAll(cmd *exec.Cmd) error {
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+func (c *command) runError(err error, operation string, diagnostics []string, logFilePath string) error {
+	message := fmt.Sprintf("error executing terraform %s", operation)
+	if len(diagnostics) > 0 {
+		message = fmt.Sprintf("%s: %s", message, strings.Join(diagnostics, "\n"))
+	}
+	if logFilePath != "" {
+		message = fmt.Sprintf("%s\n(full output saved on %s)", message, logFilePath)
+	}
+
+	return errors.Wrap(err, message)
+}
+
+// openLogFile creates the log file for a terraform run, returning it with its path.
+// If no log path is configured, returns a writer that discards the output.
+func (c *command) openLogFile(operation string) (io.WriteCloser, string, error) {
+	if c.logPath == "" {
+		return nopWriteCloser{ioutil.Discard}, "", nil
+	}
+
+	err := c.fs.MkdirAll(c.logPath, 0755)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "couldn't create directory %s", c.logPath)
+	}
+
+	// timestamp with nanoseconds, so runs don't share log file
+	timestamp := time.Now().Format("20060102T150405.000000000")
+	operation = strings.Replace(operation, " ", "-", -1)
+
+	var file afero.File
+	var path string
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("%s_%s.log", timestamp, operation)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d_%s.log", timestamp, i, operation)
+		}
+		path = filepath.Join(c.logPath, name)
 
-	err := cmd.Run()
+		file, err = c.fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			break
+		}
+	}
 	if err != nil {
-		return errors.Wrap(err, "error executing terraform init")
+		return nil, "", errors.Wrapf(err, "couldn't open log file %s", path)
 	}
+	logrus.WithField("file", path).Debug("saving terraform output")
+
+	c.removeOldLogFiles()
+
+	return file, path, nil
+}
+
+// removeOldLogFiles removes the oldest log files when there are more than maxLogFiles.
+// Errors are only logged, as they don't prevent terraform from running.
+func (c *command) removeOldLogFiles() {
+	entries, err := afero.ReadDir(c.fs, c.logPath)
+	if err != nil {
+		logrus.WithError(err).Warning("couldn't list terraform log files")
+		return
+	}
+
+	// entries are sorted by name, that starts with the run timestamp
+	logFiles := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
+			logFiles = append(logFiles, entry.Name())
+		}
+	}
+
+	for i := 0; i < len(logFiles)-maxLogFiles; i++ {
+		path := filepath.Join(c.logPath, logFiles[i])
+		err = c.fs.Remove(path)
+		if err != nil {
+			logrus.WithError(err).WithField("file", path).Warning("couldn't remove old terraform log file")
+		}
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
 
+func (nopWriteCloser) Close() error {
 	return nil
 }
@@ -1,3 +1,112 @@
 package terraformcli
 
-// TODO
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestRunError(t *testing.T) {
+	c := &command{}
+
+	err := c.runError(errors.New("exit status 1"), "apply", []string{"Error: failed to create"}, "/logs/20200101T000000_apply.log")
+
+	expected := "error executing terraform apply: Error: failed to create\n(full output saved on /logs/20200101T000000_apply.log): exit status 1"
+	if !reflect.DeepEqual(expected, err.Error()) {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expected, err.Error())
+	}
+}
+
+func TestOpenLogFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := &command{fs: fs}
+	c.SetLogPath("/deployment/logs")
+
+	file, path, err := c.openLogFile("state mv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte("output"))
+	file.Close()
+
+	if !strings.HasPrefix(path, "/deployment/logs/") || !strings.HasSuffix(path, "_state-mv.log") {
+		t.Errorf("Incorrect log file path: %s", path)
+	}
+
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual("output", string(content)) {
+		t.Errorf("Incorrect log file content.\n\n Expected: %v\n\n Obtained: %v\n", "output", string(content))
+	}
+}
+
+func TestOpenLogFileUnique(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := &command{fs: fs}
+	c.SetLogPath("/deployment/logs")
+
+	paths := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		file, path, err := c.openLogFile("apply")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+		paths[path] = true
+	}
+
+	if len(paths) != 3 {
+		t.Errorf("Incorrect number of log files.\n\n Expected: %v\n\n Obtained: %v\n", 3, len(paths))
+	}
+}
+
+func TestOpenLogFileRemovesOldLogs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := &command{fs: fs}
+	c.SetLogPath("/deployment/logs")
+
+	for i := 0; i < maxLogFiles; i++ {
+		name := fmt.Sprintf("20200101T%06d.000000000_apply.log", i)
+		err := afero.WriteFile(fs, filepath.Join("/deployment/logs", name), []byte("output"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, path, err := c.openLogFile("apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	entries, err := afero.ReadDir(fs, "/deployment/logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxLogFiles {
+		t.Errorf("Incorrect number of log files.\n\n Expected: %v\n\n Obtained: %v\n", maxLogFiles, len(entries))
+	}
+
+	ok, err := afero.Exists(fs, "/deployment/logs/20200101T000000.000000000_apply.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Oldest log file wasn't removed")
+	}
+
+	ok, err = afero.Exists(fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("New log file was removed")
+	}
+}
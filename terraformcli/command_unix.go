@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package terraformcli
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup runs terraform on its own process group, so interrupts from
+// the terminal are only received by sonatina, that forwards them.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+}
+
+func interruptProcess(process *os.Process) error {
+	return process.Signal(os.Interrupt)
+}
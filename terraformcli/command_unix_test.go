@@ -0,0 +1,84 @@
+//go:build !windows
+// +build !windows
+
+package terraformcli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRunInterruptedByTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	c := &command{}
+	cmd := exec.Command("sh", "-c", "trap 'exit 130' INT; sleep 10 & wait")
+
+	start := time.Now()
+	err := c.run(ctx, cmd)
+	if errors.Cause(err) != context.DeadlineExceeded {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", context.DeadlineExceeded, err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Command wasn't interrupted")
+	}
+}
+
+func TestRunWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := &command{}
+	cmd := exec.Command("true")
+
+	err := c.run(ctx, cmd)
+	if errors.Cause(err) != context.Canceled {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", context.Canceled, err)
+	}
+	if cmd.Process != nil {
+		t.Errorf("Command was executed")
+	}
+}
+
+func TestRunPrintingAllWithOutput(t *testing.T) {
+	stdout, err := ioutil.TempFile("", "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stdout.Name())
+	defer stdout.Close()
+
+	original := os.Stdout
+	os.Stdout = stdout
+	defer func() { os.Stdout = original }()
+
+	var out bytes.Buffer
+	c := &command{}
+	c.SetOutput(&out)
+	cmd := exec.Command("sh", "-c", "echo terraform output")
+
+	err = c.runPrintingAll(context.Background(), cmd, "plan")
+	os.Stdout = original
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	printed, err := ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(printed) != 0 {
+		t.Errorf("Incorrect standard output.\n\n Expected: %q\n\n Obtained: %q\n", "", printed)
+	}
+	if out.String() != "terraform output\n" {
+		t.Errorf("Incorrect output.\n\n Expected: %q\n\n Obtained: %q\n", "terraform output\n", out.String())
+	}
+}
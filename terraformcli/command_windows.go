@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package terraformcli
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// interruptProcess kills the process, as sending interrupts to a child process
+// isn't supported on windows.
+func interruptProcess(process *os.Process) error {
+	return process.Kill()
+}
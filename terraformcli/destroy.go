@@ -1,23 +1,33 @@
 package terraformcli
 
 import (
+	"context"
 	"os/exec"
 
 	"github.com/sirupsen/logrus"
 )
 
-func (t *Terraform) Destroy(path string, varFiles []string, stateFile string) error {
+// Destroy executes `terraform destroy`. If targets are specified, only those
+// resource addresses are destroyed.
+func (t *Terraform) Destroy(ctx context.Context, path string, varFiles []string, stateFile string, targets []string) error {
 	args := []string{}
 	args = append(args, "destroy")
 	args = append(args, t.destroyDefaultOptions().array()...)
 	args = append(args, t.varFilesOptions(varFiles).array()...)
 	args = append(args, t.stateFileOption(stateFile).render())
+	args = append(args, t.targetOptions(targets).array()...)
+	if t.supportsJSONOutput() {
+		args = append(args, t.jsonOption().render())
+	}
 	logrus.WithField("args", args).Info("executing terraform command")
 
 	cmd := exec.Command(t.BinaryPath(), args...)
 	cmd.Dir = path
 
-	return t.runPrintingAll(cmd)
+	if t.supportsJSONOutput() {
+		return t.runPrintingJSON(ctx, cmd, "destroy")
+	}
+	return t.runPrintingAll(ctx, cmd, "destroy")
 }
 
 func (t *Terraform) destroyDefaultOptions() *options {
@@ -0,0 +1,106 @@
+package terraformcli
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EngineTerraform identifies Hashicorp Terraform
+	EngineTerraform string = "terraform"
+	// EngineOpenTofu identifies OpenTofu, the open source terraform fork
+	EngineOpenTofu string = "opentofu"
+)
+
+// Engine describes a terraform compatible tool and where its releases are published.
+// URL templates can use {version} and {arch} placeholders.
+type Engine struct {
+	Name       string
+	BinaryName string
+
+	DownloadURL  string
+	ChecksumURL  string
+	SignatureURL string
+
+	// SigningKey is the armored public key used to verify the signature of the
+	// checksums file. The signature isn't verified if it's empty.
+	SigningKey string
+}
+
+var engines = map[string]Engine{
+	EngineTerraform: {
+		Name:         EngineTerraform,
+		BinaryName:   "terraform",
+		DownloadURL:  "https://releases.hashicorp.com/terraform/{version}/terraform_{version}_{arch}.zip",
+		ChecksumURL:  "https://releases.hashicorp.com/terraform/{version}/terraform_{version}_SHA256SUMS",
+		SignatureURL: "https://releases.hashicorp.com/terraform/{version}/terraform_{version}_SHA256SUMS.sig",
+		SigningKey:   hashicorpSigningKey,
+	},
+	EngineOpenTofu: {
+		Name:         EngineOpenTofu,
+		BinaryName:   "tofu",
+		DownloadURL:  "https://github.com/opentofu/opentofu/releases/download/v{version}/tofu_{version}_{arch}.zip",
+		ChecksumURL:  "https://github.com/opentofu/opentofu/releases/download/v{version}/tofu_{version}_SHA256SUMS",
+		SignatureURL: "https://github.com/opentofu/opentofu/releases/download/v{version}/tofu_{version}_SHA256SUMS.gpgsig",
+		SigningKey:   opentofuSigningKey,
+	},
+}
+
+// GetEngine returns the engine with the specified name. Empty name returns
+// terraform engine, to maintain compatibility with deployments created before
+// engine selection was supported.
+func GetEngine(name string) (Engine, error) {
+	if name == "" {
+		name = EngineTerraform
+	}
+
+	engine, ok := engines[name]
+	if !ok {
+		return Engine{}, errors.Errorf("unsupported engine %s, must be one of: %s", name, strings.Join(ListEngines(), ", "))
+	}
+
+	return engine, nil
+}
+
+// ListEngines returns the names of the supported engines
+func ListEngines() []string {
+	list := []string{}
+	for name := range engines {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// WithMirror returns a copy of the engine that downloads releases from a mirror,
+// that can be an URL or a local directory. Mirror layout must be
+// <mirror>/<engine name>/<version>/<release file>.
+func (e Engine) WithMirror(mirror string) Engine {
+	base := strings.TrimSuffix(mirror, "/") + "/" + e.Name + "/{version}/"
+
+	e.DownloadURL = base + path.Base(e.DownloadURL)
+	e.ChecksumURL = base + path.Base(e.ChecksumURL)
+	e.SignatureURL = base + path.Base(e.SignatureURL)
+	return e
+}
+
+func (e Engine) downloadURL(version string, arch string) string {
+	return e.renderURL(e.DownloadURL, version, arch)
+}
+
+func (e Engine) checksumURL(version string, arch string) string {
+	return e.renderURL(e.ChecksumURL, version, arch)
+}
+
+func (e Engine) signatureURL(version string, arch string) string {
+	return e.renderURL(e.SignatureURL, version, arch)
+}
+
+func (e Engine) renderURL(template string, version string, arch string) string {
+	replacer := strings.NewReplacer("{version}", version, "{arch}", arch)
+	return replacer.Replace(template)
+}
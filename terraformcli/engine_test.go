@@ -0,0 +1,97 @@
+package terraformcli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func TestGetEngineDefault(t *testing.T) {
+	engine, err := GetEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(EngineTerraform, engine.Name) {
+		t.Errorf("Incorrect default engine.\n\n Expected: %v\n\n Obtained: %v\n", EngineTerraform, engine.Name)
+	}
+}
+
+func TestGetEngineUnsupported(t *testing.T) {
+	_, err := GetEngine("invalid")
+	if err == nil {
+		t.Errorf("Expected error for unsupported engine, obtained nil")
+	}
+}
+
+func TestEngineSigningKeys(t *testing.T) {
+	for _, name := range ListEngines() {
+		engine, err := GetEngine(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = openpgp.ReadArmoredKeyRing(strings.NewReader(engine.SigningKey))
+		if err != nil {
+			t.Errorf("Incorrect signing key for engine %s: %v", name, err)
+		}
+	}
+}
+
+func TestEngineSigningKeysNotExpired(t *testing.T) {
+	for _, name := range ListEngines() {
+		engine, err := GetEngine(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(engine.SigningKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, entity := range keyring {
+			_, ok := entity.SigningKey(time.Now())
+			if !ok {
+				t.Errorf("Signing key of engine %s %X is expired or has no signing subkey", name, entity.PrimaryKey.Fingerprint)
+			}
+		}
+	}
+}
+
+func TestEngineDownloadURL(t *testing.T) {
+	engine, err := GetEngine(EngineOpenTofu)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://github.com/opentofu/opentofu/releases/download/v1.6.0/tofu_1.6.0_linux_amd64.zip"
+	obtained := engine.downloadURL("1.6.0", "linux_amd64")
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect download url.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestEngineWithMirror(t *testing.T) {
+	engine, err := GetEngine(EngineTerraform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine = engine.WithMirror("/srv/mirror/")
+
+	expected := "/srv/mirror/terraform/0.13.5/terraform_0.13.5_linux_amd64.zip"
+	obtained := engine.downloadURL("0.13.5", "linux_amd64")
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect download url.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+
+	expected = "/srv/mirror/terraform/0.13.5/terraform_0.13.5_SHA256SUMS"
+	obtained = engine.checksumURL("0.13.5", "linux_amd64")
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect checksum url.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
@@ -1,21 +1,29 @@
 package terraformcli
 
 import (
+	"context"
+	"os"
 	"os/exec"
 
 	"github.com/sirupsen/logrus"
 )
 
-func (t *Terraform) Init(path string) error {
+func (t *Terraform) Init(ctx context.Context, path string) error {
 	args := []string{}
 	args = append(args, "init")
 	args = append(args, t.initDefaultOptions().array()...)
 	logrus.WithField("args", args).Info("executing terraform command")
 
+	env, err := t.initEnv(path)
+	if err != nil {
+		return err
+	}
+
 	cmd := exec.Command(t.BinaryPath(), args...)
 	cmd.Dir = path
+	cmd.Env = append(os.Environ(), env...)
 
-	return t.runPrintingAll(cmd)
+	return t.runPrintingAll(ctx, cmd, "init")
 }
 
 func (t *Terraform) initDefaultOptions() *options {
@@ -45,9 +45,39 @@ func (t *Terraform) varFilesOptions(varFiles []string) *options {
 	return &options
 }
 
+func (t *Terraform) targetOptions(targets []string) *options {
+	options := options{}
+
+	for _, target := range targets {
+		option := option{
+			key:   "target",
+			value: target,
+		}
+		options = append(options, option)
+	}
+
+	return &options
+}
+
+func (t *Terraform) jsonOption() *option {
+	return &option{
+		key:   "json",
+		value: "",
+	}
+}
+
 func (t *Terraform) stateFileOption(stateFile string) *option {
 	return &option{
 		key:   "-state",
 		value: stateFile,
 	}
 }
+
+// stateBackupOption sets where terraform writes the backup of the state file
+// modified by a command, that would be written next to it by default
+func (t *Terraform) stateBackupOption(backupFile string) *option {
+	return &option{
+		key:   "backup",
+		value: backupFile,
+	}
+}
@@ -0,0 +1,163 @@
+package terraformcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tailWriter keeps the last lines written to it, used to report the
+// diagnostics of a failed terraform run.
+type tailWriter struct {
+	max     int
+	lines   []string
+	partial string
+}
+
+func newTailWriter(max int) *tailWriter {
+	return &tailWriter{
+		max:   max,
+		lines: []string{},
+	}
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	data := t.partial + string(p)
+	lines := strings.Split(data, "\n")
+
+	t.partial = lines[len(lines)-1]
+	t.lines = append(t.lines, lines[:len(lines)-1]...)
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+
+	return len(p), nil
+}
+
+// diagnostics returns the last error block printed by terraform, starting on the
+// last line with an "Error:" prefix. If there isn't any, returns the last lines.
+func (t *tailWriter) diagnostics() []string {
+	lines := append([]string{}, t.lines...)
+	if t.partial != "" {
+		lines = append(lines, t.partial)
+	}
+
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "Error:") {
+			start = i
+		}
+	}
+	if start < 0 {
+		start = len(lines) - 5
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	diagnostics := []string{}
+	for _, line := range lines[start:] {
+		if strings.TrimSpace(line) != "" {
+			diagnostics = append(diagnostics, strings.TrimRight(line, " \r"))
+		}
+	}
+
+	return diagnostics
+}
+
+// jsonUIMessage is a partial representation of the messages printed by
+// terraform when using the -json flag.
+type jsonUIMessage struct {
+	Level      string `json:"@level"`
+	Message    string `json:"@message"`
+	Type       string `json:"type"`
+	Diagnostic *struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+		Detail   string `json:"detail"`
+	} `json:"diagnostic"`
+}
+
+// jsonUI parses terraform machine readable UI output, printing human readable
+// messages prefixed with the progress of resource changes.
+type jsonUI struct {
+	out     io.Writer
+	partial []byte
+
+	planned   int
+	completed int
+	errors    []string
+}
+
+func newJSONUI(out io.Writer) *jsonUI {
+	return &jsonUI{
+		out:    out,
+		errors: []string{},
+	}
+}
+
+func (u *jsonUI) Write(p []byte) (int, error) {
+	u.partial = append(u.partial, p...)
+
+	for {
+		i := bytes.IndexByte(u.partial, '\n')
+		if i < 0 {
+			break
+		}
+		u.processLine(u.partial[:i])
+		u.partial = u.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush processes any pending output without line ending
+func (u *jsonUI) Flush() {
+	if len(u.partial) > 0 {
+		u.processLine(u.partial)
+		u.partial = nil
+	}
+}
+
+// diagnostics returns the error diagnostics printed by terraform
+func (u *jsonUI) diagnostics() []string {
+	return u.errors
+}
+
+func (u *jsonUI) processLine(line []byte) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+
+	message := jsonUIMessage{}
+	err := json.Unmarshal(line, &message)
+	if err != nil {
+		// Not a machine readable message, print as is
+		fmt.Fprintln(u.out, string(line))
+		return
+	}
+
+	switch message.Type {
+	case "planned_change":
+		u.planned++
+	case "apply_complete", "apply_errored":
+		u.completed++
+	}
+
+	if message.Diagnostic != nil && message.Diagnostic.Severity == "error" {
+		diagnostic := "Error: " + message.Diagnostic.Summary
+		if message.Diagnostic.Detail != "" {
+			diagnostic = diagnostic + ": " + message.Diagnostic.Detail
+		}
+		u.errors = append(u.errors, diagnostic)
+	}
+
+	switch message.Type {
+	case "apply_start", "apply_progress", "apply_complete", "apply_errored":
+		fmt.Fprintf(u.out, "[%d/%d] %s\n", u.completed, u.planned, message.Message)
+	default:
+		fmt.Fprintln(u.out, message.Message)
+	}
+}
@@ -0,0 +1,69 @@
+package terraformcli
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTailWriterDiagnostics(t *testing.T) {
+	tail := newTailWriter(maxTailLines)
+	tail.Write([]byte("Initializing provider plugins...\n"))
+	tail.Write([]byte("\nError: Invalid reference\n\n  on main.tf line 3:\n   3:   name = foo.bar\n"))
+	tail.Write([]byte("\nA reference to a resource type must be followed"))
+
+	expected := []string{
+		"Error: Invalid reference",
+		"  on main.tf line 3:",
+		"   3:   name = foo.bar",
+		"A reference to a resource type must be followed",
+	}
+	obtained := tail.diagnostics()
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect diagnostics.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestTailWriterDiagnosticsWithoutError(t *testing.T) {
+	tail := newTailWriter(3)
+	tail.Write([]byte("line1\nline2\nline3\nline4\nline5\n"))
+
+	expected := []string{"line3", "line4", "line5"}
+	obtained := tail.diagnostics()
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect diagnostics.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestJSONUIProgress(t *testing.T) {
+	var out bytes.Buffer
+	ui := newJSONUI(&out)
+
+	ui.Write([]byte(`{"@level":"info","@message":"Terraform 1.0.0","type":"version"}
+{"@level":"info","@message":"null_resource.a: Plan to create","type":"planned_change"}
+{"@level":"info","@message":"null_resource.b: Plan to create","type":"planned_change"}
+{"@level":"info","@message":"null_resource.a: Creating...","type":"apply_start"}
+{"@level":"info","@message":"null_resource.a: Creation complete after 0s","type":"apply_complete"}
+{"@level":"info","@message":"null_resource.b: Creating...","type":"apply_start"}
+{"@level":"error","@message":"null_resource.b: Creation errored after 0s","type":"apply_errored"}
+{"@level":"error","@message":"Error: failed to create","type":"diagnostic","diagnostic":{"severity":"error","summary":"failed to create","detail":"permission denied"}}`))
+	ui.Flush()
+
+	expectedOutput := `Terraform 1.0.0
+null_resource.a: Plan to create
+null_resource.b: Plan to create
+[0/2] null_resource.a: Creating...
+[1/2] null_resource.a: Creation complete after 0s
+[1/2] null_resource.b: Creating...
+[2/2] null_resource.b: Creation errored after 0s
+Error: failed to create
+`
+	if !reflect.DeepEqual(expectedOutput, out.String()) {
+		t.Errorf("Incorrect output.\n\n Expected:\n%s\n\n Obtained:\n%s\n", expectedOutput, out.String())
+	}
+
+	expectedDiagnostics := []string{"Error: failed to create: permission denied"}
+	if !reflect.DeepEqual(expectedDiagnostics, ui.diagnostics()) {
+		t.Errorf("Incorrect diagnostics.\n\n Expected: %v\n\n Obtained: %v\n", expectedDiagnostics, ui.diagnostics())
+	}
+}
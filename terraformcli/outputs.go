@@ -0,0 +1,54 @@
+package terraformcli
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// OutputValue is a root module output as returned by `terraform output -json`
+type OutputValue struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// String returns the output value as a string. Non-string values are returned
+// JSON encoded.
+func (o OutputValue) String() string {
+	var s string
+	err := json.Unmarshal(o.Value, &s)
+	if err != nil {
+		return string(o.Value)
+	}
+	return s
+}
+
+// Output executes `terraform output` over the specified state file, returning
+// the root module outputs.
+func (t *Terraform) Output(ctx context.Context, path string, stateFile string) (map[string]OutputValue, error) {
+	args := []string{}
+	args = append(args, "output")
+	args = append(args, t.jsonOption().render())
+	args = append(args, t.stateFileOption(stateFile).render())
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	output, err := t.runCapturingOutput(ctx, cmd, "output")
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]OutputValue{}
+	err = json.Unmarshal(output, &outputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't unmarshal terraform output json")
+	}
+
+	return outputs, nil
+}
@@ -0,0 +1,31 @@
+package terraformcli
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOutputValueString(t *testing.T) {
+	outputs := map[string]OutputValue{}
+	err := json.Unmarshal([]byte(`{
+  "bucket": {"sensitive": false, "type": "string", "value": "my-bucket"},
+  "ports": {"sensitive": false, "type": ["list", "number"], "value": [80, 443]}
+}`), &outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"bucket": "my-bucket",
+		"ports":  "[80, 443]",
+	}
+	obtained := map[string]string{}
+	for name, output := range outputs {
+		obtained[name] = output.String()
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect outputs.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
@@ -0,0 +1,103 @@
+package terraformcli
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const planFileName string = "sonatina.tfplan"
+
+// Plan is a partial representation of the terraform plan JSON output, as
+// returned by `terraform show -json`.
+type Plan struct {
+	ResourceDrift   []ResourceChange `json:"resource_drift"`
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+}
+
+// ResourceChange describes the actions that terraform plans for a resource
+type ResourceChange struct {
+	Address string `json:"address"`
+	Change  struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// PlanRefreshOnly executes a refresh-only `terraform plan`, returning the parsed
+// plan. Requires terraform 0.15.4 or later.
+func (t *Terraform) PlanRefreshOnly(ctx context.Context, path string, varFiles []string, stateFile string) (*Plan, error) {
+	if !t.supportsRefreshOnly() {
+		return nil, errors.Errorf("refresh-only plans need %s %s or later, but version %s is used",
+			t.engine.Name, refreshOnlyMinVersion, t.version)
+	}
+
+	planFile := filepath.Join(path, planFileName)
+	defer t.fs.Remove(planFile)
+
+	args := []string{}
+	args = append(args, "plan")
+	args = append(args, t.planRefreshOnlyDefaultOptions().array()...)
+	args = append(args, t.varFilesOptions(varFiles).array()...)
+	args = append(args, t.stateFileOption(stateFile).render())
+	args = append(args, t.outFileOption(planFile).render())
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	err := t.runPrintingAll(ctx, cmd, "plan")
+	if err != nil {
+		return nil, err
+	}
+
+	return t.showPlan(ctx, path, planFile)
+}
+
+func (t *Terraform) showPlan(ctx context.Context, path string, planFile string) (*Plan, error) {
+	args := []string{"show", "-json", planFile}
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	output, err := t.runCapturingOutput(ctx, cmd, "show")
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	err = json.Unmarshal(output, plan)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't unmarshal terraform plan json")
+	}
+
+	return plan, nil
+}
+
+func (t *Terraform) planRefreshOnlyDefaultOptions() *options {
+	return &options{
+		option{
+			key:   "refresh-only",
+			value: "",
+		},
+		option{
+			key:   "input",
+			value: "false",
+		},
+		option{
+			key:   "no-color",
+			value: "",
+		},
+	}
+}
+
+func (t *Terraform) outFileOption(planFile string) *option {
+	return &option{
+		key:   "out",
+		value: planFile,
+	}
+}
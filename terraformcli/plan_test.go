@@ -0,0 +1,19 @@
+package terraformcli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestPlanRefreshOnlyUnsupportedVersion(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	terraform := Open(fs, "/terraform", engines[EngineTerraform], "0.13.5", "linux_amd64", "/bin/false")
+
+	_, err := terraform.PlanRefreshOnly(context.Background(), "/workdir", []string{}, "/state/terraform.tfstate")
+	expected := "refresh-only plans need terraform 0.15.4 or later, but version 0.13.5 is used"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Incorrect error.\n\n Expected: %v\n\n Obtained: %v\n", expected, err)
+	}
+}
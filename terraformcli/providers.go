@@ -0,0 +1,161 @@
+package terraformcli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const cliConfigFileName string = "terraformrc"
+const pluginCacheDirName string = "plugin-cache"
+
+// SetProviderMirror configures terraform to install providers only from the specified
+// filesystem mirror directory, without accessing the provider registry.
+func (t *Terraform) SetProviderMirror(path string) {
+	t.providerMirror = path
+}
+
+// ProvidersMirror executes `terraform providers mirror`, downloading to mirrorPath the
+// providers required by the configuration for the specified platforms.
+func (t *Terraform) ProvidersMirror(ctx context.Context, path string, mirrorPath string, platforms []string) error {
+	args := []string{}
+	args = append(args, "providers", "mirror")
+	args = append(args, t.platformOptions(platforms).array()...)
+	args = append(args, mirrorPath)
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	return t.runPrintingAll(ctx, cmd, "providers mirror")
+}
+
+// initEnv returns the environment variables used by `terraform init` on the path
+// directory, that configure provider installation.
+func (t *Terraform) initEnv(path string) ([]string, error) {
+	env, err := t.pluginCacheEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	cliConfigEnv, err := t.cliConfigEnv(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(env, cliConfigEnv...), nil
+}
+
+// pluginCacheEnv returns the environment variables needed to use the provider plugin
+// cache shared by all deployments and components, creating the cache directory.
+func (t *Terraform) pluginCacheEnv() ([]string, error) {
+	path := filepath.Join(t.path, pluginCacheDirName)
+
+	err := t.fs.MkdirAll(path, 0755)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't create directory %s", path)
+	}
+
+	return []string{"TF_PLUGIN_CACHE_DIR=" + path}, nil
+}
+
+// cliConfigEnv generates the terraform CLI configuration file on the path directory,
+// returning the environment variables needed to use it. The user CLI configuration
+// is kept, but its provider installation settings are replaced to use the provider
+// mirror. No configuration is generated if there isn't any provider mirror configured.
+func (t *Terraform) cliConfigEnv(path string) ([]string, error) {
+	if t.providerMirror == "" {
+		return []string{}, nil
+	}
+
+	config, err := t.userCLIConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	body := config.Body()
+	for _, block := range body.Blocks() {
+		if block.Type() == "provider_installation" {
+			body.RemoveBlock(block)
+		}
+	}
+
+	installation := body.AppendNewBlock("provider_installation", nil)
+	mirror := installation.Body().AppendNewBlock("filesystem_mirror", nil)
+	mirror.Body().SetAttributeValue("path", cty.StringVal(t.providerMirror))
+
+	filePath := filepath.Join(path, cliConfigFileName)
+	err = afero.WriteFile(t.fs, filePath, hclwrite.Format(config.Bytes()), 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't write terraform cli config file %s", filePath)
+	}
+
+	return []string{"TF_CLI_CONFIG_FILE=" + filePath}, nil
+}
+
+// userCLIConfig returns the terraform CLI configuration of the user, or an empty
+// configuration if the user doesn't have one.
+func (t *Terraform) userCLIConfig() (*hclwrite.File, error) {
+	path, err := userCLIConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := afero.ReadFile(t.fs, path)
+	if os.IsNotExist(err) {
+		return hclwrite.NewEmptyFile(), nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read terraform cli config file %s", path)
+	}
+
+	config, diags := hclwrite.ParseConfig(content, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "couldn't parse terraform cli config file %s", path)
+	}
+
+	return config, nil
+}
+
+// userCLIConfigPath returns the path of the terraform CLI configuration used when
+// sonatina doesn't generate one.
+func userCLIConfigPath() (string, error) {
+	path := os.Getenv("TF_CLI_CONFIG_FILE")
+	if path != "" {
+		return path, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "terraform.rc"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get user home directory")
+	}
+
+	return filepath.Join(home, ".terraformrc"), nil
+}
+
+func (t *Terraform) platformOptions(platforms []string) *options {
+	options := options{}
+
+	for _, platform := range platforms {
+		option := option{
+			key:   "platform",
+			value: platform,
+		}
+		options = append(options, option)
+	}
+
+	return &options
+}
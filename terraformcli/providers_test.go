@@ -0,0 +1,141 @@
+package terraformcli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestCLIConfigEnvWithProviderMirror(t *testing.T) {
+	t.Setenv("TF_CLI_CONFIG_FILE", "/home/user/.terraformrc")
+
+	fs := afero.NewMemMapFs()
+	terraform := &Terraform{
+		fs:   fs,
+		path: "/terraform",
+	}
+	terraform.SetProviderMirror("/mirror/providers")
+
+	env, err := terraform.cliConfigEnv("/workdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEnv := []string{"TF_CLI_CONFIG_FILE=/workdir/terraformrc"}
+	if !reflect.DeepEqual(expectedEnv, env) {
+		t.Errorf("Incorrect environment.\n\n Expected: %v\n\n Obtained: %v\n", expectedEnv, env)
+	}
+
+	expectedContent := `provider_installation {
+  filesystem_mirror {
+    path = "/mirror/providers"
+  }
+}
+`
+	obtainedContent, err := afero.ReadFile(fs, "/workdir/terraformrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedContent, string(obtainedContent)) {
+		t.Errorf("Incorrect cli config.\n\n Expected:\n%s\n\n Obtained:\n%s\n", expectedContent, string(obtainedContent))
+	}
+}
+
+func TestCLIConfigEnvWithUserConfig(t *testing.T) {
+	t.Setenv("TF_CLI_CONFIG_FILE", "/home/user/.terraformrc")
+
+	fs := afero.NewMemMapFs()
+	userConfig := `credentials "app.terraform.io" {
+  token = "secret"
+}
+
+provider_installation {
+  direct {}
+}
+`
+	err := afero.WriteFile(fs, "/home/user/.terraformrc", []byte(userConfig), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terraform := &Terraform{
+		fs:   fs,
+		path: "/terraform",
+	}
+	terraform.SetProviderMirror("/mirror/providers")
+
+	_, err = terraform.cliConfigEnv("/workdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedContent := `credentials "app.terraform.io" {
+  token = "secret"
+}
+
+provider_installation {
+  filesystem_mirror {
+    path = "/mirror/providers"
+  }
+}
+`
+	obtainedContent, err := afero.ReadFile(fs, "/workdir/terraformrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedContent, string(obtainedContent)) {
+		t.Errorf("Incorrect cli config.\n\n Expected:\n%s\n\n Obtained:\n%s\n", expectedContent, string(obtainedContent))
+	}
+}
+
+func TestCLIConfigEnvWithoutProviderMirror(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	terraform := &Terraform{
+		fs:   fs,
+		path: "/terraform",
+	}
+
+	env, err := terraform.cliConfigEnv("/workdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(env) != 0 {
+		t.Errorf("Expected empty environment, obtained %v", env)
+	}
+}
+
+func TestInitEnv(t *testing.T) {
+	t.Setenv("TF_CLI_CONFIG_FILE", "/home/user/.terraformrc")
+
+	fs := afero.NewMemMapFs()
+	terraform := &Terraform{
+		fs:   fs,
+		path: "/terraform",
+	}
+	terraform.SetProviderMirror("/mirror/providers")
+
+	env, err := terraform.initEnv("/workdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEnv := []string{
+		"TF_PLUGIN_CACHE_DIR=/terraform/plugin-cache",
+		"TF_CLI_CONFIG_FILE=/workdir/terraformrc",
+	}
+	if !reflect.DeepEqual(expectedEnv, env) {
+		t.Errorf("Incorrect environment.\n\n Expected: %v\n\n Obtained: %v\n", expectedEnv, env)
+	}
+
+	ok, err := afero.DirExists(fs, "/terraform/plugin-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("Expected plugin cache directory to be created")
+	}
+}
@@ -0,0 +1,102 @@
+package terraformcli
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stateBackupFile is the state backup written by commands that modify the state.
+// It's kept on the execution path, so it isn't pushed with the state repository.
+const stateBackupFile string = "terraform.tfstate.backup"
+
+// StateList executes `terraform state list` over the specified state file.
+func (t *Terraform) StateList(ctx context.Context, path string, stateFile string) error {
+	args := []string{}
+	args = append(args, "state", "list")
+	args = append(args, t.stateFileOption(stateFile).render())
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	return t.runPrintingAll(ctx, cmd, "state list")
+}
+
+// StateShow executes `terraform state show` for a resource address over the
+// specified state file.
+func (t *Terraform) StateShow(ctx context.Context, path string, stateFile string, address string) error {
+	args := []string{}
+	args = append(args, "state", "show")
+	args = append(args, t.stateFileOption(stateFile).render())
+	args = append(args, address)
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	return t.runPrintingAll(ctx, cmd, "state show")
+}
+
+// StateMv executes `terraform state mv` to move an item in the specified state file.
+func (t *Terraform) StateMv(ctx context.Context, path string, stateFile string, source string, destination string) error {
+	args := []string{}
+	args = append(args, "state", "mv")
+	args = append(args, t.stateFileOption(stateFile).render())
+	args = append(args, t.stateBackupOption(filepath.Join(path, stateBackupFile)).render())
+	args = append(args, source, destination)
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	return t.runPrintingAll(ctx, cmd, "state mv")
+}
+
+// StateRm executes `terraform state rm` to remove items from the specified state file.
+func (t *Terraform) StateRm(ctx context.Context, path string, stateFile string, addresses []string) error {
+	args := []string{}
+	args = append(args, "state", "rm")
+	args = append(args, t.stateFileOption(stateFile).render())
+	args = append(args, t.stateBackupOption(filepath.Join(path, stateBackupFile)).render())
+	args = append(args, addresses...)
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	return t.runPrintingAll(ctx, cmd, "state rm")
+}
+
+// Import executes `terraform import` to bring an existing resource under the
+// management of the specified state file.
+func (t *Terraform) Import(ctx context.Context, path string, varFiles []string, stateFile string, address string, id string) error {
+	args := []string{}
+	args = append(args, "import")
+	args = append(args, t.importDefaultOptions().array()...)
+	args = append(args, t.varFilesOptions(varFiles).array()...)
+	args = append(args, t.stateFileOption(stateFile).render())
+	args = append(args, t.stateBackupOption(filepath.Join(path, stateBackupFile)).render())
+	args = append(args, address, id)
+	logrus.WithField("args", args).Info("executing terraform command")
+
+	cmd := exec.Command(t.BinaryPath(), args...)
+	cmd.Dir = path
+
+	return t.runPrintingAll(ctx, cmd, "import")
+}
+
+func (t *Terraform) importDefaultOptions() *options {
+	return &options{
+		option{
+			key:   "input",
+			value: "false",
+		},
+		option{
+			key:   "no-color",
+			value: "",
+		},
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !windows
+// +build !windows
+
+package terraformcli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestStateMv(t *testing.T) {
+	terraform, out, path := testNewEchoTerraform(t)
+	defer os.RemoveAll(path)
+
+	err := terraform.StateMv(context.Background(), path, "/state/terraform.tfstate", "null_resource.a", "null_resource.b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "state mv --state=/state/terraform.tfstate -backup=" + filepath.Join(path, stateBackupFile) + " null_resource.a null_resource.b\n"
+	if out.String() != expected {
+		t.Errorf("Incorrect arguments.\n\n Expected: %v\n\n Obtained: %v\n", expected, out.String())
+	}
+}
+
+func TestStateRm(t *testing.T) {
+	terraform, out, path := testNewEchoTerraform(t)
+	defer os.RemoveAll(path)
+
+	err := terraform.StateRm(context.Background(), path, "/state/terraform.tfstate", []string{"null_resource.a", "null_resource.b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "state rm --state=/state/terraform.tfstate -backup=" + filepath.Join(path, stateBackupFile) + " null_resource.a null_resource.b\n"
+	if out.String() != expected {
+		t.Errorf("Incorrect arguments.\n\n Expected: %v\n\n Obtained: %v\n", expected, out.String())
+	}
+}
+
+func TestImport(t *testing.T) {
+	terraform, out, path := testNewEchoTerraform(t)
+	defer os.RemoveAll(path)
+
+	err := terraform.Import(context.Background(), path, []string{"/vars/global.tfvars"}, "/state/terraform.tfstate", "null_resource.a", "id-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "import -input=false -no-color --var-file=/vars/global.tfvars --state=/state/terraform.tfstate -backup=" +
+		filepath.Join(path, stateBackupFile) + " null_resource.a id-1\n"
+	if out.String() != expected {
+		t.Errorf("Incorrect arguments.\n\n Expected: %v\n\n Obtained: %v\n", expected, out.String())
+	}
+}
+
+// testNewEchoTerraform returns a terraform whose binary prints its arguments, the
+// buffer where they are printed and a temporary directory to run it
+func testNewEchoTerraform(t *testing.T) (*Terraform, *bytes.Buffer, string) {
+	path, err := ioutil.TempDir("", "terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := filepath.Join(path, "terraform")
+	err = ioutil.WriteFile(binary, []byte("#!/bin/sh\necho \"$@\"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	terraform := Open(afero.NewOsFs(), path, engines[EngineTerraform], "0.13.5", "linux_amd64", binary)
+	terraform.SetOutput(out)
+
+	return terraform, out, path
+}
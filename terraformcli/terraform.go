@@ -8,40 +8,62 @@ type Terraform struct {
 	fs   afero.Fs
 	path string
 
+	providerMirror string
+
 	binary
 	command
 }
 
-// New constructs a new Terraform struct and returns it
-func New(fs afero.Fs, path string, version string, arch string) (*Terraform, error) {
+// New constructs a new Terraform struct and returns it. If localBinary is set, it's
+// used as the terraform binary instead of downloading the engine release.
+func New(fs afero.Fs, path string, engine Engine, version string, arch string, localBinary string) (*Terraform, error) {
+	terraform := Open(fs, path, engine, version, arch, localBinary)
+	return terraform, terraform.Install()
+}
+
+// Open constructs a new Terraform struct without checking or downloading its binary
+func Open(fs afero.Fs, path string, engine Engine, version string, arch string, localBinary string) *Terraform {
 	binary := binary{
-		fs:      fs,
-		path:    path,
-		version: version,
-		arch:    arch,
+		fs:        fs,
+		path:      path,
+		engine:    engine,
+		version:   version,
+		arch:      arch,
+		localPath: localBinary,
 	}
-	terraform := &Terraform{
+	return &Terraform{
 		fs:   fs,
 		path: path,
 
 		binary: binary,
+		command: command{
+			fs: fs,
+		},
 	}
+}
 
-	err := fs.MkdirAll(path, 0755)
+// Install downloads the terraform binary if it doesn't exist or it doesn't match
+// the checksum saved when it was downloaded
+func (t *Terraform) Install() error {
+	err := t.fs.MkdirAll(t.path, 0755)
 	if err != nil {
-		return terraform, err
+		return err
 	}
 
-	ok, err := terraform.checkBinary()
+	ok, err := t.checkBinary()
 	if err != nil {
-		return terraform, err
+		return err
 	}
 	if !ok {
-		err = terraform.getBinary()
-		if err != nil {
-			return terraform, err
-		}
+		return t.getBinary()
 	}
 
-	return terraform, nil
+	return nil
+}
+
+// VerifyBinary returns true if the terraform binary exists and, for downloaded
+// binaries, it matches the checksum saved when it was downloaded. Local binaries
+// must match the engine and version of the deployment.
+func (t *Terraform) VerifyBinary() (bool, error) {
+	return t.checkBinary()
 }
@@ -139,6 +139,50 @@ func FileCopyRecursively(fs afero.Fs, sourcePath string, destPath string) error
 	return nil
 }
 
+// DirectoryMove moves a directory to a new path, that must not exist. If the
+// source directory doesn't exist, an empty one is created on the new path.
+func DirectoryMove(fs afero.Fs, sourcePath string, destPath string) error {
+	ok, err := afero.Exists(fs, destPath)
+	if err != nil {
+		return errors.Wrap(err, "couldn't determine if file exists")
+	}
+	if ok {
+		return errors.Errorf("directory %s already exists", destPath)
+	}
+
+	ok, err = afero.DirExists(fs, sourcePath)
+	if err != nil {
+		return errors.Wrap(err, "couldn't determine if directory exists")
+	}
+	if !ok {
+		err = fs.MkdirAll(destPath, 0755)
+		return errors.Wrapf(err, "couldn't create directory %s", destPath)
+	}
+
+	err = fs.MkdirAll(filepath.Dir(destPath), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't create directory %s", filepath.Dir(destPath))
+	}
+
+	err = fs.Rename(sourcePath, destPath)
+	if err == nil {
+		return nil
+	}
+
+	// Rename fails across devices or filesystems, so the directory is copied and
+	// then removed
+	err = fs.MkdirAll(destPath, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't create directory %s", destPath)
+	}
+	err = FileCopyRecursively(fs, sourcePath, destPath)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't move directory %s to %s", sourcePath, destPath)
+	}
+	err = fs.RemoveAll(sourcePath)
+	return errors.Wrapf(err, "couldn't remove directory %s", sourcePath)
+}
+
 // FileListRecursively list recursively all files inside a directory. The result list
 // is alphanumerically ordered
 func FileListRecursively(fs afero.Fs, path string) ([]string, error) {
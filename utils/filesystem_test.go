@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"os"
 	"reflect"
 	"sort"
 	"strings"
@@ -163,6 +165,92 @@ func TestFileCopyRecursively(t *testing.T) {
 	}
 }
 
+func TestDirectoryMove(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/src/user1/terraform.tfstate", []byte("state"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DirectoryMove(fs, "/src/user1", "/dst/user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := afero.ReadFile(fs, "/dst/user2/terraform.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "state" {
+		t.Errorf("Incorrect moved file content.\n\n Expected: %v\n\n Obtained: %v\n", "state", string(content))
+	}
+
+	ok, err := afero.Exists(fs, "/src/user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Source directory must not exist after move")
+	}
+}
+
+func TestDirectoryMoveWithRenameError(t *testing.T) {
+	fs := &testRenameErrorFs{afero.NewMemMapFs()}
+
+	err := afero.WriteFile(fs, "/src/user1/terraform.tfstate", []byte("state"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DirectoryMove(fs, "/src/user1", "/dst/user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := afero.ReadFile(fs, "/dst/user2/terraform.tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "state" {
+		t.Errorf("Incorrect moved file content.\n\n Expected: %v\n\n Obtained: %v\n", "state", string(content))
+	}
+
+	ok, err := afero.Exists(fs, "/src/user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Source directory must not exist after move")
+	}
+}
+
+// testRenameErrorFs is a filesystem where renames fail, like across devices
+type testRenameErrorFs struct {
+	afero.Fs
+}
+
+func (fs *testRenameErrorFs) Rename(oldname string, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errors.New("invalid cross-device link")}
+}
+
+func TestDirectoryMoveWithUnexistingSource(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := DirectoryMove(fs, "/src/user1", "/dst/user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := afero.DirExists(fs, "/dst/user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("Destination directory must be created")
+	}
+}
+
 func TestFileListRecursively(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	path := "/list"
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -31,6 +32,27 @@ func HTTPDownloadFile(fs afero.Fs, file afero.File, url string) error {
 	return nil
 }
 
+// HTTPGet downloads a url returning its content. Intended for small files,
+// use HTTPDownloadFile otherwise.
+func HTTPGet(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't perform HTTP GET over %s", url)
+	}
+	defer resp.Body.Close()
+
+	err = httpCheckCorrectResponseCode(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't read response body")
+	}
+	return content, nil
+}
+
 func httpCheckCorrectResponseCode(resp *http.Response) error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return errors.New(fmt.Sprintf("HTTP Response with Status Code %s", resp.Status))
@@ -44,3 +44,34 @@ func TestHTTPDownloadFile(t *testing.T) {
 		t.Errorf("Incorrect downloaded content.\n\n Expected: %v\n\n Obtained: %v\n", expectedContent, obtainedContent)
 	}
 }
+
+func TestHTTPGet(t *testing.T) {
+	expectedContent := "Test"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, expectedContent)
+	}))
+	defer ts.Close()
+
+	obtainedContentBytes, err := HTTPGet(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obtainedContent := string(obtainedContentBytes)
+
+	if !reflect.DeepEqual(expectedContent, obtainedContent) {
+		t.Errorf("Incorrect downloaded content.\n\n Expected: %v\n\n Obtained: %v\n", expectedContent, obtainedContent)
+	}
+}
+
+func TestHTTPGetWithErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	_, err := HTTPGet(ts.URL)
+	if err == nil {
+		t.Errorf("Expected error for not found response, obtained nil")
+	}
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompareVersions compares two versions with major.minor.patch format, returning -1, 0
+// or 1 if a is lower, equal or greater than b. Missing components are considered zero
+// and a "v" prefix or build suffixes are ignored. As in semantic versioning, a version
+// with a prerelease suffix, like 1.0.0-rc1, is lower than the version without it.
+func CompareVersions(a string, b string) (int, error) {
+	aVersion, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+
+	bVersion, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range aVersion.parts {
+		if aVersion.parts[i] < bVersion.parts[i] {
+			return -1, nil
+		}
+		if aVersion.parts[i] > bVersion.parts[i] {
+			return 1, nil
+		}
+	}
+
+	return comparePrereleases(aVersion.prerelease, bVersion.prerelease), nil
+}
+
+// ValidateVersion returns an error if version doesn't have major.minor.patch format,
+// accepting the same variations as CompareVersions
+func ValidateVersion(version string) error {
+	_, err := parseVersion(version)
+	return err
+}
+
+type version struct {
+	parts      [3]int
+	prerelease string
+}
+
+func parseVersion(value string) (version, error) {
+	parsed := version{}
+
+	trimmed := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	if i := strings.Index(trimmed, "+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	if i := strings.Index(trimmed, "-"); i >= 0 {
+		parsed.prerelease = trimmed[i+1:]
+		trimmed = trimmed[:i]
+		if parsed.prerelease == "" {
+			return parsed, errors.Errorf("invalid version %s", value)
+		}
+	}
+
+	fields := strings.Split(trimmed, ".")
+	if trimmed == "" || len(fields) > 3 {
+		return parsed, errors.Errorf("invalid version %s", value)
+	}
+
+	for i, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 {
+			return parsed, errors.Errorf("invalid version %s", value)
+		}
+		parsed.parts[i] = number
+	}
+
+	return parsed, nil
+}
+
+// comparePrereleases compares two prerelease suffixes with semantic versioning
+// precedence: no prerelease is greater than any prerelease, and dot separated
+// identifiers are compared numerically if they're numbers or lexically otherwise.
+func comparePrereleases(a string, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	aFields := strings.Split(a, ".")
+	bFields := strings.Split(b, ".")
+	for i := 0; i < len(aFields) && i < len(bFields); i++ {
+		result := comparePrereleaseIdentifiers(aFields[i], bFields[i])
+		if result != 0 {
+			return result
+		}
+	}
+
+	switch {
+	case len(aFields) < len(bFields):
+		return -1
+	case len(aFields) > len(bFields):
+		return 1
+	}
+	return 0
+}
+
+// comparePrereleaseIdentifiers compares two prerelease identifiers, numeric ones
+// being lower than alphanumeric ones
+func comparePrereleaseIdentifiers(a string, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNumber < bNumber {
+			return -1
+		}
+		if aNumber > bNumber {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// CheckVersionConstraint returns true if version satisfies the constraint. Constraints
+// are comma separated conditions that must be met, each one with an operator (=, !=, >,
+// >=, <, <= or ~>) followed by a version. A version without operator means equality.
+// The pessimistic operator ~> allows only the rightmost version component to increase.
+func CheckVersionConstraint(version string, constraint string) (bool, error) {
+	for _, condition := range strings.Split(constraint, ",") {
+		ok, err := checkVersionCondition(version, strings.TrimSpace(condition))
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ValidateVersionConstraint returns an error if constraint hasn't the format accepted
+// by CheckVersionConstraint
+func ValidateVersionConstraint(constraint string) error {
+	for _, condition := range strings.Split(constraint, ",") {
+		_, target := splitVersionCondition(strings.TrimSpace(condition))
+		err := ValidateVersion(target)
+		if err != nil {
+			return errors.Wrapf(err, "invalid version constraint %s", condition)
+		}
+	}
+
+	return nil
+}
+
+// splitVersionCondition returns the operator and the version of a condition
+func splitVersionCondition(condition string) (string, string) {
+	operator := ""
+	for _, op := range []string{"~>", ">=", "<=", "!=", ">", "<", "="} {
+		if strings.HasPrefix(condition, op) {
+			operator = op
+			break
+		}
+	}
+
+	return operator, strings.TrimSpace(strings.TrimPrefix(condition, operator))
+}
+
+func checkVersionCondition(version string, condition string) (bool, error) {
+	operator, target := splitVersionCondition(condition)
+
+	result, err := CompareVersions(version, target)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid version constraint %s", condition)
+	}
+
+	switch operator {
+	case "", "=":
+		return result == 0, nil
+	case "!=":
+		return result != 0, nil
+	case ">":
+		return result > 0, nil
+	case ">=":
+		return result >= 0, nil
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	}
+
+	// Pessimistic constraint: ~> 1.2 means >= 1.2, < 2.0 and ~> 1.2.3 means >= 1.2.3, < 1.3.0
+	if result < 0 {
+		return false, nil
+	}
+	parsed, _ := parseVersion(target)
+	parts := parsed.parts
+	fields := len(strings.Split(strings.TrimPrefix(target, "v"), "."))
+	upper := [3]int{}
+	if fields <= 1 {
+		upper[0] = parts[0] + 1
+	} else {
+		copy(upper[:], parts[:fields-2])
+		upper[fields-2] = parts[fields-2] + 1
+	}
+
+	result, err = CompareVersions(version, fmt.Sprintf("%d.%d.%d", upper[0], upper[1], upper[2]))
+	if err != nil {
+		return false, err
+	}
+	return result < 0, nil
+}
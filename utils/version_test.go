@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"0.13.5", "0.13.5", 0},
+		{"0.13.5", "0.15.3", -1},
+		{"1.0.0", "0.15.3", 1},
+		{"v1.2", "1.2.0", 0},
+		{"1.6.0-beta1", "1.6.0", -1},
+		{"1.6.0", "1.6.0-beta1", 1},
+		{"1.6.0-beta1", "1.6.0-beta2", -1},
+		{"1.6.0-rc.2", "1.6.0-rc.10", -1},
+		{"1.6.0-1", "1.6.0-alpha", -1},
+		{"1.6.0-alpha", "1.6.0-alpha.1", -1},
+		{"1.6.0-beta1+build", "1.6.0-beta1", 0},
+		{"1.6.0+build", "1.6.0", 0},
+		{"0.9.10", "0.9.2", 1},
+	}
+
+	for _, test := range tests {
+		obtained, err := CompareVersions(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if obtained != test.expected {
+			t.Errorf("Incorrect comparison of %s and %s, expected: %v, obtained: %v", test.a, test.b, test.expected, obtained)
+		}
+	}
+}
+
+func TestCompareVersionsInvalid(t *testing.T) {
+	for _, version := range []string{"", "a.b.c", "1.2.3.4"} {
+		_, err := CompareVersions(version, "1.0.0")
+		if err == nil {
+			t.Errorf("Expected error for invalid version %q, obtained nil", version)
+		}
+	}
+}
+
+func TestCheckVersionConstraint(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		expected   bool
+	}{
+		{"0.13.5", ">= 0.13.0, < 0.15.0", true},
+		{"0.15.0", ">= 0.13.0, < 0.15.0", false},
+		{"0.13.5", "0.13.5", true},
+		{"0.13.5", "!= 0.13.5", false},
+		{"0.14.9", "~> 0.14.2", true},
+		{"0.15.0", "~> 0.14.2", false},
+		{"1.9.0", "~> 1.2", true},
+		{"2.0.0", "~> 1.2", false},
+		{"1.1.0", "~> 1.2", false},
+		{"1.0.0-rc1", ">= 1.0.0", false},
+		{"1.0.0-rc1", ">= 1.0.0-rc1", true},
+	}
+
+	for _, test := range tests {
+		obtained, err := CheckVersionConstraint(test.version, test.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if obtained != test.expected {
+			t.Errorf("Incorrect check of %s against %s, expected: %v, obtained: %v", test.version, test.constraint, test.expected, obtained)
+		}
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	for _, version := range []string{"1", "1.2", "v1.2.3", "1.2.3-beta1"} {
+		err := ValidateVersion(version)
+		if err != nil {
+			t.Errorf("Unexpected error for version %q: %v", version, err)
+		}
+	}
+
+	for _, version := range []string{"", "a.b.c", "1.2.3.4", "1..2"} {
+		err := ValidateVersion(version)
+		if err == nil {
+			t.Errorf("Expected error for invalid version %q, obtained nil", version)
+		}
+	}
+}
+
+func TestValidateVersionConstraint(t *testing.T) {
+	for _, constraint := range []string{"1.2.3", ">= 0.12, < 0.14", "~> 1.2", "!=0.13.0"} {
+		err := ValidateVersionConstraint(constraint)
+		if err != nil {
+			t.Errorf("Unexpected error for constraint %q: %v", constraint, err)
+		}
+	}
+
+	for _, constraint := range []string{"", ">= a", "1.0,", "=> 1.0"} {
+		err := ValidateVersionConstraint(constraint)
+		if err == nil {
+			t.Errorf("Expected error for invalid constraint %q, obtained nil", constraint)
+		}
+	}
+}
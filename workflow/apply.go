@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"context"
+
 	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/terraformcli"
 )
@@ -17,61 +19,65 @@ func Apply(terraform *terraformcli.Terraform, deployment deployment.Deployment)
 	}
 }
 
-func (i *ApplyWorkflow) RunGlobal(message string) error {
-	executionPath, err := i.Deployment.GenerateWorkdirGlobal()
+func (i *ApplyWorkflow) RunGlobal(ctx context.Context, message string) error {
+	return i.RunComponent(ctx, message, deployment.ComponentGlobal, "")
+}
+
+func (i *ApplyWorkflow) RunUser(ctx context.Context, message string, user string) error {
+	return i.RunComponent(ctx, message, deployment.ComponentUser, user)
+}
+
+// RunComponent applies an instance of a component type. Instance is ignored for
+// the global component.
+func (i *ApplyWorkflow) RunComponent(ctx context.Context, message string, component string, instance string) error {
+	executionPath, err := i.Deployment.GenerateWorkdirComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	variableFiles, err := i.Deployment.GenerateVariablesGlobal()
+	variableFiles, err := i.Deployment.GenerateVariablesComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	stateFile := i.Deployment.StateFilePathGlobal()
+	stateFile := i.Deployment.StateFilePathComponent(component, instance)
 
-	err = i.Terraform.Init(executionPath)
+	err = runHooks(ctx, i.Deployment, deployment.HookPreInit, component, instance, executionPath, nil)
 	if err != nil {
 		return err
 	}
 
-	err = i.Terraform.Apply(executionPath, variableFiles, stateFile)
+	err = i.Terraform.Init(ctx, executionPath)
 	if err != nil {
 		return err
 	}
 
-	err = i.Deployment.Push(message)
+	err = i.Deployment.SaveLockFileComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (i *ApplyWorkflow) RunUser(message string, user string) error {
-	executionPath, err := i.Deployment.GenerateWorkdirUser(user)
+	err = runHooks(ctx, i.Deployment, deployment.HookPreApply, component, instance, executionPath, nil)
 	if err != nil {
 		return err
 	}
 
-	variableFiles, err := i.Deployment.GenerateVariablesUser(user)
+	err = i.Terraform.Apply(ctx, executionPath, variableFiles, stateFile)
 	if err != nil {
-		return err
+		return pushFailed(ctx, i.Deployment, message, err)
 	}
 
-	stateFile := i.Deployment.StateFilePathUser(user)
-
-	err = i.Terraform.Init(executionPath)
+	err = i.Deployment.Push(message)
 	if err != nil {
 		return err
 	}
 
-	err = i.Terraform.Apply(executionPath, variableFiles, stateFile)
+	outputs, err := i.Terraform.Output(ctx, executionPath, stateFile)
 	if err != nil {
 		return err
 	}
 
-	err = i.Deployment.Push(message)
+	err = runHooks(ctx, i.Deployment, deployment.HookPostApply, component, instance, executionPath, outputs)
 	if err != nil {
 		return err
 	}
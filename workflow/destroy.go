@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"context"
+
 	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/terraformcli"
 )
@@ -17,61 +19,72 @@ func Destroy(terraform *terraformcli.Terraform, deployment deployment.Deployment
 	}
 }
 
-func (i *DestroyWorkflow) RunGlobal(message string) error {
-	executionPath, err := i.Deployment.GenerateWorkdirGlobal()
-	if err != nil {
-		return err
-	}
+func (i *DestroyWorkflow) RunGlobal(ctx context.Context, message string) error {
+	return i.RunComponentTargets(ctx, message, deployment.ComponentGlobal, "", nil)
+}
+
+func (i *DestroyWorkflow) RunUser(ctx context.Context, message string, user string) error {
+	return i.RunUserTargets(ctx, message, user, nil)
+}
+
+// RunUserTargets destroys only the specified resource addresses of the user
+// component. All its resources are destroyed if targets is empty.
+func (i *DestroyWorkflow) RunUserTargets(ctx context.Context, message string, user string, targets []string) error {
+	return i.RunComponentTargets(ctx, message, deployment.ComponentUser, user, targets)
+}
+
+// RunComponent destroys an instance of a component type. Instance is ignored for
+// the global component.
+func (i *DestroyWorkflow) RunComponent(ctx context.Context, message string, component string, instance string) error {
+	return i.RunComponentTargets(ctx, message, component, instance, nil)
+}
 
-	variableFiles, err := i.Deployment.GenerateVariablesGlobal()
+// RunComponentTargets destroys only the specified resource addresses of a component
+// instance. All its resources are destroyed if targets is empty.
+func (i *DestroyWorkflow) RunComponentTargets(ctx context.Context, message string, component string, instance string, targets []string) error {
+	executionPath, err := i.Deployment.GenerateWorkdirComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	stateFile := i.Deployment.StateFilePathGlobal()
-
-	err = i.Terraform.Init(executionPath)
+	variableFiles, err := i.Deployment.GenerateVariablesComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	err = i.Terraform.Destroy(executionPath, variableFiles, stateFile)
+	stateFile := i.Deployment.StateFilePathComponent(component, instance)
+
+	err = runHooks(ctx, i.Deployment, deployment.HookPreInit, component, instance, executionPath, nil)
 	if err != nil {
 		return err
 	}
 
-	err = i.Deployment.Push(message)
+	err = i.Terraform.Init(ctx, executionPath)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (i *DestroyWorkflow) RunUser(message string, user string) error {
-	executionPath, err := i.Deployment.GenerateWorkdirUser(user)
+	err = i.Deployment.SaveLockFileComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	variableFiles, err := i.Deployment.GenerateVariablesUser(user)
+	err = runHooks(ctx, i.Deployment, deployment.HookPreDestroy, component, instance, executionPath, nil)
 	if err != nil {
 		return err
 	}
 
-	stateFile := i.Deployment.StateFilePathUser(user)
-
-	err = i.Terraform.Init(executionPath)
+	err = i.Terraform.Destroy(ctx, executionPath, variableFiles, stateFile, targets)
 	if err != nil {
-		return err
+		return pushFailed(ctx, i.Deployment, message, err)
 	}
 
-	err = i.Terraform.Destroy(executionPath, variableFiles, stateFile)
+	err = i.Deployment.Push(message)
 	if err != nil {
 		return err
 	}
 
-	err = i.Deployment.Push(message)
+	err = runHooks(ctx, i.Deployment, deployment.HookPostDestroy, component, instance, executionPath, nil)
 	if err != nil {
 		return err
 	}
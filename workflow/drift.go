@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+)
+
+// DriftWorkflow detects differences between the real infrastructure and the
+// state of the deployment components, running refresh-only plans.
+type DriftWorkflow struct {
+	Terraform  *terraformcli.Terraform
+	Deployment deployment.Deployment
+}
+
+// ComponentDrift lists the resources that have changed outside of sonatina for
+// a component. User is set for user components, and Instance for the other
+// component types declared by the base CTD.
+type ComponentDrift struct {
+	Component string            `json:"component"`
+	User      string            `json:"user,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Resources []DriftedResource `json:"resources"`
+}
+
+// DriftedResource describes a resource whose real infrastructure doesn't match state
+type DriftedResource struct {
+	Address string   `json:"address"`
+	Actions []string `json:"actions"`
+}
+
+func Drift(terraform *terraformcli.Terraform, deployment deployment.Deployment) *DriftWorkflow {
+	return &DriftWorkflow{
+		Terraform:  terraform,
+		Deployment: deployment,
+	}
+}
+
+// HasDrift returns true if any resource of the component has drifted
+func (c *ComponentDrift) HasDrift() bool {
+	return len(c.Resources) > 0
+}
+
+func (d *DriftWorkflow) RunGlobal(ctx context.Context) (*ComponentDrift, error) {
+	return d.RunComponent(ctx, deployment.ComponentGlobal, "")
+}
+
+func (d *DriftWorkflow) RunUser(ctx context.Context, user string) (*ComponentDrift, error) {
+	return d.RunComponent(ctx, deployment.ComponentUser, user)
+}
+
+func (d *DriftWorkflow) RunComponent(ctx context.Context, component string, instance string) (*ComponentDrift, error) {
+	executionPath, err := d.Deployment.GenerateWorkdirComponent(component, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	variableFiles, err := d.Deployment.GenerateVariablesComponent(component, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	stateFile := d.Deployment.StateFilePathComponent(component, instance)
+
+	err = d.Terraform.Init(ctx, executionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := d.Terraform.PlanRefreshOnly(ctx, executionPath, variableFiles, stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return newComponentDrift(component, instance, plan), nil
+}
+
+func newComponentDrift(component string, instance string, plan *terraformcli.Plan) *ComponentDrift {
+	drift := &ComponentDrift{
+		Component: component,
+		Resources: []DriftedResource{},
+	}
+	switch component {
+	case deployment.ComponentGlobal:
+	case deployment.ComponentUser:
+		drift.User = instance
+	default:
+		drift.Instance = instance
+	}
+
+	changes := append([]terraformcli.ResourceChange{}, plan.ResourceDrift...)
+	changes = append(changes, plan.ResourceChanges...)
+	for _, change := range changes {
+		if !isDriftAction(change.Change.Actions) {
+			continue
+		}
+		drift.Resources = append(drift.Resources, DriftedResource{
+			Address: change.Address,
+			Actions: change.Change.Actions,
+		})
+	}
+
+	return drift
+}
+
+func isDriftAction(actions []string) bool {
+	for _, action := range actions {
+		if action != "no-op" && action != "read" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+)
+
+func TestNewComponentDrift(t *testing.T) {
+	plan := &terraformcli.Plan{
+		ResourceDrift: []terraformcli.ResourceChange{
+			testResourceChange("aws_instance.web", "update"),
+			testResourceChange("aws_instance.db", "delete"),
+		},
+		ResourceChanges: []terraformcli.ResourceChange{
+			testResourceChange("aws_instance.web", "no-op"),
+			testResourceChange("data.aws_ami.ubuntu", "read"),
+			testResourceChange("aws_s3_bucket.logs", "delete", "create"),
+		},
+	}
+
+	obtained := newComponentDrift("database", "main", plan)
+	expected := &ComponentDrift{
+		Component: "database",
+		Instance:  "main",
+		Resources: []DriftedResource{
+			{Address: "aws_instance.web", Actions: []string{"update"}},
+			{Address: "aws_instance.db", Actions: []string{"delete"}},
+			{Address: "aws_s3_bucket.logs", Actions: []string{"delete", "create"}},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect drift.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+}
+
+func TestNewComponentDriftUser(t *testing.T) {
+	obtained := newComponentDrift(deployment.ComponentUser, "alice", &terraformcli.Plan{})
+	expected := &ComponentDrift{
+		Component: deployment.ComponentUser,
+		User:      "alice",
+		Resources: []DriftedResource{},
+	}
+
+	if !reflect.DeepEqual(expected, obtained) {
+		t.Errorf("Incorrect drift.\n\n Expected: %v\n\n Obtained: %v\n", expected, obtained)
+	}
+	if obtained.HasDrift() {
+		t.Errorf("Component without drifted resources must not have drift")
+	}
+}
+
+func TestIsDriftAction(t *testing.T) {
+	cases := map[string]struct {
+		actions  []string
+		expected bool
+	}{
+		"no-op":   {[]string{"no-op"}, false},
+		"read":    {[]string{"read"}, false},
+		"empty":   {[]string{}, false},
+		"update":  {[]string{"update"}, true},
+		"replace": {[]string{"delete", "create"}, true},
+	}
+
+	for name, c := range cases {
+		obtained := isDriftAction(c.actions)
+		if obtained != c.expected {
+			t.Errorf("Incorrect drift action for %s.\n\n Expected: %v\n\n Obtained: %v\n", name, c.expected, obtained)
+		}
+	}
+}
+
+func testResourceChange(address string, actions ...string) terraformcli.ResourceChange {
+	change := terraformcli.ResourceChange{Address: address}
+	change.Change.Actions = actions
+	return change
+}
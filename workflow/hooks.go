@@ -0,0 +1,101 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var invalidEnvChars = regexp.MustCompile("[^A-Z0-9_]")
+
+// runHooks executes the hooks declared by the component CTDs for the event,
+// stopping on the first failure. Instance is ignored for the global component.
+func runHooks(ctx context.Context, d deployment.Deployment, event string, component string, instance string,
+	executionPath string, outputs map[string]terraformcli.OutputValue) error {
+
+	hooks, err := d.ListHooksComponent(event, component, instance)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		logrus.WithFields(logrus.Fields{
+			"event":   event,
+			"ctd":     hook.CTD,
+			"command": hook.Command,
+		}).Info("executing hook")
+		fmt.Printf("Running %s hook from %s: %s\n", event, hook.CTD, hook.Command)
+
+		cmd := hookCommand(ctx, hook.Command)
+		cmd.Dir = hook.Path
+		cmd.Env = append(os.Environ(), hookEnv(event, component, instance, executionPath, outputs, hook.SensitiveOutputs)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		err = cmd.Run()
+		if err != nil {
+			return errors.Wrapf(err, "%s hook from %s failed", event, hook.CTD)
+		}
+	}
+
+	return nil
+}
+
+// hookEnv returns the environment variables that describe the operation to hooks.
+// Each output is available on a SONATINA_OUTPUT_<NAME> variable, and all of them
+// JSON encoded on SONATINA_OUTPUTS. Sensitive outputs are left out unless sensitive
+// is set. SONATINA_USER is only set for user components.
+func hookEnv(event string, component string, instance string, executionPath string,
+	outputs map[string]terraformcli.OutputValue, sensitive bool) []string {
+
+	user := ""
+	if component == deployment.ComponentUser {
+		user = instance
+	}
+
+	env := []string{
+		"SONATINA_HOOK=" + event,
+		"SONATINA_WORKDIR=" + executionPath,
+		"SONATINA_COMPONENT=" + component,
+		"SONATINA_INSTANCE=" + instance,
+		"SONATINA_USER=" + user,
+	}
+
+	names := []string{}
+	values := []string{}
+	for name, output := range outputs {
+		if output.Sensitive && !sensitive {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, fmt.Sprintf("SONATINA_OUTPUT_%s=%s", envName(name), outputs[name].String()))
+		values = append(values, fmt.Sprintf("%q:%s", name, outputs[name].Value))
+	}
+	env = append(env, fmt.Sprintf("SONATINA_OUTPUTS={%s}", strings.Join(values, ",")))
+
+	return env
+}
+
+func envName(name string) string {
+	return invalidEnvChars.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"context"
+
 	"github.com/arodriguezdlc/sonatina/deployment"
 	"github.com/arodriguezdlc/sonatina/terraformcli"
 )
@@ -17,37 +19,38 @@ func Init(terraform *terraformcli.Terraform, deployment deployment.Deployment) *
 	}
 }
 
-func (i *InitWorkflow) RunGlobal() error {
-	executionPath, err := i.Deployment.GenerateWorkdirGlobal()
-	if err != nil {
-		return err
-	}
+func (i *InitWorkflow) RunGlobal(ctx context.Context) error {
+	return i.RunComponent(ctx, deployment.ComponentGlobal, "")
+}
 
-	_, err = i.Deployment.GenerateVariablesGlobal()
+func (i *InitWorkflow) RunUser(ctx context.Context, user string) error {
+	return i.RunComponent(ctx, deployment.ComponentUser, user)
+}
+
+// RunComponent initializes an instance of a component type. Instance is ignored
+// for the global component.
+func (i *InitWorkflow) RunComponent(ctx context.Context, component string, instance string) error {
+	executionPath, err := i.Deployment.GenerateWorkdirComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	err = i.Terraform.Init(executionPath)
+	_, err = i.Deployment.GenerateVariablesComponent(component, instance)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (i *InitWorkflow) RunUser(user string) error {
-	executionPath, err := i.Deployment.GenerateWorkdirUser(user)
+	err = runHooks(ctx, i.Deployment, deployment.HookPreInit, component, instance, executionPath, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = i.Deployment.GenerateVariablesUser(user)
+	err = i.Terraform.Init(ctx, executionPath)
 	if err != nil {
 		return err
 	}
 
-	err = i.Terraform.Init(executionPath)
+	err = i.Deployment.SaveLockFileComponent(component, instance)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+)
+
+// MirrorWorkflow downloads to a local mirror the providers required by the
+// deployment components, so they can be installed without internet access.
+type MirrorWorkflow struct {
+	Terraform  *terraformcli.Terraform
+	Deployment deployment.Deployment
+}
+
+func Mirror(terraform *terraformcli.Terraform, deployment deployment.Deployment) *MirrorWorkflow {
+	return &MirrorWorkflow{
+		Terraform:  terraform,
+		Deployment: deployment,
+	}
+}
+
+func (m *MirrorWorkflow) RunGlobal(ctx context.Context, mirrorPath string, platforms []string) error {
+	return m.RunComponent(ctx, mirrorPath, platforms, deployment.ComponentGlobal, "")
+}
+
+func (m *MirrorWorkflow) RunUser(ctx context.Context, mirrorPath string, platforms []string, user string) error {
+	return m.RunComponent(ctx, mirrorPath, platforms, deployment.ComponentUser, user)
+}
+
+func (m *MirrorWorkflow) RunComponent(ctx context.Context, mirrorPath string, platforms []string, component string, instance string) error {
+	executionPath, err := m.Deployment.GenerateWorkdirComponent(component, instance)
+	if err != nil {
+		return err
+	}
+
+	return m.Terraform.ProvidersMirror(ctx, executionPath, mirrorPath, platforms)
+}
@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+)
+
+// OutputWorkflow retrieves the outputs of the deployment components from their state
+type OutputWorkflow struct {
+	Terraform  *terraformcli.Terraform
+	Deployment deployment.Deployment
+}
+
+func Output(terraform *terraformcli.Terraform, deployment deployment.Deployment) *OutputWorkflow {
+	return &OutputWorkflow{
+		Terraform:  terraform,
+		Deployment: deployment,
+	}
+}
+
+func (o *OutputWorkflow) RunGlobal(ctx context.Context) (map[string]terraformcli.OutputValue, error) {
+	return o.RunComponent(ctx, deployment.ComponentGlobal, "")
+}
+
+func (o *OutputWorkflow) RunUser(ctx context.Context, user string) (map[string]terraformcli.OutputValue, error) {
+	return o.RunComponent(ctx, deployment.ComponentUser, user)
+}
+
+func (o *OutputWorkflow) RunComponent(ctx context.Context, component string, instance string) (map[string]terraformcli.OutputValue, error) {
+	executionPath, err := o.Deployment.GenerateWorkdirComponent(component, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Terraform.Output(ctx, executionPath, o.Deployment.StateFilePathComponent(component, instance))
+}
@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// pushFailed commits and pushes the state written by a failed or interrupted
+// terraform execution, so partial changes aren't only kept locally. The commit
+// message is marked accordingly. Returns the original error, annotated if the
+// push fails too.
+func pushFailed(ctx context.Context, d deployment.Deployment, message string, err error) error {
+	mark := "[FAILED]"
+	if ctx.Err() != nil {
+		mark = "[INTERRUPTED]"
+	}
+
+	pushErr := d.Push(fmt.Sprintf("%s %s", mark, message))
+	if pushErr != nil {
+		logrus.WithError(pushErr).Error("couldn't push partial state")
+		return errors.Wrapf(err, "partial state couldn't be pushed (%v)", pushErr)
+	}
+
+	return err
+}
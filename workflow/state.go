@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/arodriguezdlc/sonatina/deployment"
+	"github.com/arodriguezdlc/sonatina/terraformcli"
+)
+
+// StateWorkflow runs terraform state operations over the state file of a
+// deployment component. Operations that modify the state push it afterwards.
+// Instance is ignored for the global component.
+type StateWorkflow struct {
+	Terraform  *terraformcli.Terraform
+	Deployment deployment.Deployment
+}
+
+func State(terraform *terraformcli.Terraform, deployment deployment.Deployment) *StateWorkflow {
+	return &StateWorkflow{
+		Terraform:  terraform,
+		Deployment: deployment,
+	}
+}
+
+// List prints the resources tracked on the component state
+func (s *StateWorkflow) List(ctx context.Context, component string, instance string) error {
+	executionPath, _, stateFile, err := s.prepare(ctx, component, instance)
+	if err != nil {
+		return err
+	}
+
+	return s.Terraform.StateList(ctx, executionPath, stateFile)
+}
+
+// Show prints the attributes of a resource tracked on the component state
+func (s *StateWorkflow) Show(ctx context.Context, component string, instance string, address string) error {
+	executionPath, _, stateFile, err := s.prepare(ctx, component, instance)
+	if err != nil {
+		return err
+	}
+
+	return s.Terraform.StateShow(ctx, executionPath, stateFile, address)
+}
+
+// Move renames or moves an item of the component state, then pushes the modified state
+func (s *StateWorkflow) Move(ctx context.Context, message string, component string, instance string, source string, destination string) error {
+	executionPath, _, stateFile, err := s.prepare(ctx, component, instance)
+	if err != nil {
+		return err
+	}
+
+	err = s.Terraform.StateMv(ctx, executionPath, stateFile, source, destination)
+	if err != nil {
+		return err
+	}
+
+	return s.Deployment.Push(message)
+}
+
+// Remove removes items from the component state, then pushes the modified state
+func (s *StateWorkflow) Remove(ctx context.Context, message string, component string, instance string, addresses []string) error {
+	executionPath, _, stateFile, err := s.prepare(ctx, component, instance)
+	if err != nil {
+		return err
+	}
+
+	err = s.Terraform.StateRm(ctx, executionPath, stateFile, addresses)
+	if err != nil {
+		return err
+	}
+
+	return s.Deployment.Push(message)
+}
+
+// Import imports an existing resource into the component state, then pushes
+// the modified state
+func (s *StateWorkflow) Import(ctx context.Context, message string, component string, instance string, address string, id string) error {
+	executionPath, variableFiles, stateFile, err := s.prepare(ctx, component, instance)
+	if err != nil {
+		return err
+	}
+
+	err = s.Terraform.Import(ctx, executionPath, variableFiles, stateFile, address, id)
+	if err != nil {
+		return err
+	}
+
+	return s.Deployment.Push(message)
+}
+
+// prepare generates workdir and variables for the component and initializes
+// terraform on it, the same way ApplyWorkflow does.
+func (s *StateWorkflow) prepare(ctx context.Context, component string, instance string) (string, []string, string, error) {
+	executionPath, err := s.Deployment.GenerateWorkdirComponent(component, instance)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	variableFiles, err := s.Deployment.GenerateVariablesComponent(component, instance)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	stateFile := s.Deployment.StateFilePathComponent(component, instance)
+
+	err = s.Terraform.Init(ctx, executionPath)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	err = s.Deployment.SaveLockFileComponent(component, instance)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return executionPath, variableFiles, stateFile, nil
+}